@@ -0,0 +1,192 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/wI2L/jsondiff"
+)
+
+// orgHash returns a stable content hash of org, which shouldSkipUnchanged
+// compares against the previous run's hash to decide whether an org
+// actually changed since the last run.
+func orgHash(org Organization) (string, error) {
+	data, err := json.Marshal(org)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// changeEntry is one line of a per-run changelog: what changed, and an
+// RFC 6902 JSON Patch describing exactly how.
+type changeEntry struct {
+	ID         string          `json:"id"`
+	WebsiteKey string          `json:"websiteKey"`
+	ChangedAt  string          `json:"changedAt"`
+	Patch      json.RawMessage `json:"patch"`
+}
+
+// ChangeLog accumulates per-run change records and writes them as
+// newline-delimited JSON to data/changes/<runID>.ndjson.
+type ChangeLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewChangeLog opens (creating as needed) data/changes/<runID>.ndjson.
+func NewChangeLog(dataDir, runID string) (*ChangeLog, error) {
+	dir := filepath.Join(dataDir, "changes")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, runID+".ndjson"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &ChangeLog{file: f}, nil
+}
+
+// Record diffs previous against current and appends a changeEntry line,
+// unless the two are identical.
+func (c *ChangeLog) Record(previous, current Organization) error {
+	ops, err := jsondiff.Compare(previous, current)
+	if err != nil {
+		return fmt.Errorf("failed to compute JSON patch: %w", err)
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		return err
+	}
+
+	entry := changeEntry{
+		ID:         fmt.Sprintf("%v", current.ID),
+		WebsiteKey: current.WebsiteKey,
+		ChangedAt:  time.Now().Format(time.RFC3339),
+		Patch:      patch,
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err = c.file.Write(append(line, '\n'))
+	return err
+}
+
+func (c *ChangeLog) Close() error {
+	return c.file.Close()
+}
+
+// listingHashPath returns where a hash of each organization's raw listing
+// payload, as it looked on the last completed run, is persisted.
+func listingHashPath(dataDir string) string {
+	return filepath.Join(dataDir, "..", "listing_hashes.json")
+}
+
+// loadListingHashes reads the previous run's listing hashes (keyed by org
+// ID), or an empty map if this is the first run.
+func loadListingHashes(dataDir string) (map[string]string, error) {
+	data, err := os.ReadFile(listingHashPath(dataDir))
+	if os.IsNotExist(err) {
+		return make(map[string]string), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]string)
+	if err := json.Unmarshal(data, &hashes); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// saveListingHashes persists the current run's listing hashes for the next
+// --incremental comparison.
+func saveListingHashes(dataDir string, hashes map[string]string) error {
+	data, err := json.MarshalIndent(hashes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(listingHashPath(dataDir), data, 0644)
+}
+
+// shouldSkipUnchanged reports whether org can be skipped entirely in
+// --incremental mode.
+//
+// org is the raw record fetched from the listing API, where every
+// "Enhanced Fields (from detail page)" field (including ModifiedOn) is
+// always empty. Comparing it against the fully detail-enriched record in
+// the store would therefore never match, so instead we hash org itself
+// and compare against the hash of what the listing API returned for this
+// org on the previous run, recorded in listingHashes. listingHashes is
+// updated in place with org's current hash for the next run.
+func shouldSkipUnchanged(listingHashes map[string]string, org Organization) (bool, error) {
+	id := fmt.Sprintf("%v", org.ID)
+	hash, err := orgHash(org)
+	if err != nil {
+		return false, err
+	}
+	prevHash, seen := listingHashes[id]
+	listingHashes[id] = hash
+	return seen && prevHash == hash, nil
+}
+
+// recordDeletedOrgs compares the orgs just fetched from the API against
+// everything in the store, and appends any that have disappeared to
+// data/deleted.json (keyed by ID, first-seen-deleted timestamp preserved).
+func recordDeletedOrgs(store Store, dataDir string, current []Organization) error {
+	seen := make(map[string]struct{}, len(current))
+	for _, org := range current {
+		seen[fmt.Sprintf("%v", org.ID)] = struct{}{}
+	}
+
+	stored, err := store.ListOrganizations()
+	if err != nil {
+		return err
+	}
+
+	deletedPath := filepath.Join(dataDir, "deleted.json")
+	existing := make(map[string]string)
+	if data, err := os.ReadFile(deletedPath); err == nil {
+		_ = json.Unmarshal(data, &existing)
+	}
+
+	newlyDeleted := 0
+	for _, org := range stored {
+		id := fmt.Sprintf("%v", org.ID)
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		if _, already := existing[id]; already {
+			continue
+		}
+		existing[id] = time.Now().Format(time.RFC3339)
+		newlyDeleted++
+	}
+
+	if newlyDeleted == 0 {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(existing, "", "  ")
+	if err != nil {
+		return err
+	}
+	log.Printf("Incremental: %d organizations newly marked deleted", newlyDeleted)
+	return os.WriteFile(deletedPath, data, 0644)
+}
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -9,11 +13,17 @@ import (
 	"math"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
+
+	"github.com/schollz/progressbar/v3"
+	"golang.org/x/text/unicode/norm"
 )
 
 // SocialMedia represents social media links
@@ -47,49 +57,49 @@ type PrimaryContact struct {
 
 // ContactInfo represents contact information
 type ContactInfo struct {
-	ID           int     `json:"id"`
-	AddressType  int     `json:"addressType"`
-	PhoneNumber  *string `json:"phoneNumber"`
-	Extension    *string `json:"extension"`
-	FaxNumber    *string `json:"faxNumber"`
-	Street1      *string `json:"street1"`
-	Street2      *string `json:"street2"`
-	City         *string `json:"city"`
-	State        *string `json:"state"`
-	Zip          *string `json:"zip"`
-	Country      *string `json:"country"`
-	Deleted      bool    `json:"deleted"`
+	ID          int     `json:"id"`
+	AddressType int     `json:"addressType"`
+	PhoneNumber *string `json:"phoneNumber"`
+	Extension   *string `json:"extension"`
+	FaxNumber   *string `json:"faxNumber"`
+	Street1     *string `json:"street1"`
+	Street2     *string `json:"street2"`
+	City        *string `json:"city"`
+	State       *string `json:"state"`
+	Zip         *string `json:"zip"`
+	Country     *string `json:"country"`
+	Deleted     bool    `json:"deleted"`
 }
 
 // OrganizationType represents organization type settings
 type OrganizationType struct {
-	ID                              int     `json:"id"`
-	BranchID                        int     `json:"branchId"`
-	Name                            string  `json:"name"`
-	ShowMembersToPublic             bool    `json:"showMembersToPublic"`
-	ShowOfficersToPublic            bool    `json:"showOfficersToPublic"`
-	ShowMembersToLoggedInUsersByDefault bool `json:"showMembersToLoggedInUsersByDefault"`
-	ShowOfficersToLoggedInUsersByDefault bool `json:"showOfficersToLoggedInUsersByDefault"`
-	AutoApproveRequests             bool    `json:"autoApproveRequests"`
-	EventsEnabled                   bool    `json:"eventsEnabled"`
-	ServiceHoursEnabled             bool    `json:"serviceHoursEnabled"`
-	FinanceEnabled                  bool    `json:"financeEnabled"`
-	FinanceRequestsEnabled          bool    `json:"financeRequestsEnabled"`
-	FundingRequestsEnabled          bool    `json:"fundingRequestsEnabled"`
-	PurchaseRequestsEnabled         bool    `json:"purchaseRequestsEnabled"`
-	BudgetingEnabled                bool    `json:"budgetingEnabled"`
-	BudgetingRequestsEnabled        bool    `json:"budgetingRequestsEnabled"`
-	ElectionsEnabled                bool    `json:"electionsEnabled"`
-	FormsEnabled                    bool    `json:"formsEnabled"`
-	GalleryEnabled                  bool    `json:"galleryEnabled"`
-	OutcomesEnabled                 bool    `json:"outcomesEnabled"`
-	RosterEnabled                   bool    `json:"rosterEnabled"`
-	DocumentsEnabled                bool    `json:"documentsEnabled"`
-	IsShownInPublicDirectory        bool    `json:"shownInPublicDirectory"`
-	IsSystemType                    bool    `json:"isSystemType"`
-	AdminOnly                       bool    `json:"adminOnly"`
-	IsClosed                        bool    `json:"isClosed"`
-	ReRegistrationAvailability      *string `json:"reRegistrationAvailabilty"`
+	ID                                   int     `json:"id"`
+	BranchID                             int     `json:"branchId"`
+	Name                                 string  `json:"name"`
+	ShowMembersToPublic                  bool    `json:"showMembersToPublic"`
+	ShowOfficersToPublic                 bool    `json:"showOfficersToPublic"`
+	ShowMembersToLoggedInUsersByDefault  bool    `json:"showMembersToLoggedInUsersByDefault"`
+	ShowOfficersToLoggedInUsersByDefault bool    `json:"showOfficersToLoggedInUsersByDefault"`
+	AutoApproveRequests                  bool    `json:"autoApproveRequests"`
+	EventsEnabled                        bool    `json:"eventsEnabled"`
+	ServiceHoursEnabled                  bool    `json:"serviceHoursEnabled"`
+	FinanceEnabled                       bool    `json:"financeEnabled"`
+	FinanceRequestsEnabled               bool    `json:"financeRequestsEnabled"`
+	FundingRequestsEnabled               bool    `json:"fundingRequestsEnabled"`
+	PurchaseRequestsEnabled              bool    `json:"purchaseRequestsEnabled"`
+	BudgetingEnabled                     bool    `json:"budgetingEnabled"`
+	BudgetingRequestsEnabled             bool    `json:"budgetingRequestsEnabled"`
+	ElectionsEnabled                     bool    `json:"electionsEnabled"`
+	FormsEnabled                         bool    `json:"formsEnabled"`
+	GalleryEnabled                       bool    `json:"galleryEnabled"`
+	OutcomesEnabled                      bool    `json:"outcomesEnabled"`
+	RosterEnabled                        bool    `json:"rosterEnabled"`
+	DocumentsEnabled                     bool    `json:"documentsEnabled"`
+	IsShownInPublicDirectory             bool    `json:"shownInPublicDirectory"`
+	IsSystemType                         bool    `json:"isSystemType"`
+	AdminOnly                            bool    `json:"adminOnly"`
+	IsClosed                             bool    `json:"isClosed"`
+	ReRegistrationAvailability           *string `json:"reRegistrationAvailabilty"`
 }
 
 // Category represents an organization category
@@ -129,7 +139,7 @@ type Organization struct {
 	WebsiteKey           string      `json:"websiteKey"`
 	ProfilePicture       string      `json:"profilePicture"`
 	ProfilePictureURL    string      `json:"profilePictureURL"`
-	Description          string      `json:"description"` 
+	Description          string      `json:"description"`
 	Summary              string      `json:"summary"`
 	CategoryIDs          []string    `json:"categoryIds"`
 	CategoryNames        []string    `json:"categoryNames"`
@@ -137,45 +147,46 @@ type Organization struct {
 	Visibility           string      `json:"visibility"`
 
 	// Enhanced Fields (from detail page)
-	Email                    string           `json:"email"`
-	CommunityID              int              `json:"communityId"`
-	NameSortKey              string           `json:"nameSortKey"`
-	Comment                  *string          `json:"comment"`
-	ShowJoin                 bool             `json:"showJoin"`
-	StatusChangeDateTime     string           `json:"statusChangeDateTime"`
-	StartDate                *string          `json:"startDate"`
-	EndDate                  *string          `json:"endDate"`
-	ParentID                 *int             `json:"parentId"`
-	WallID                   *int             `json:"wallId"`
-	DiscussionID             *int             `json:"discussionId"`
-	GroupTypeID              *int             `json:"groupTypeId"`
-	OrganizationTypeID       int              `json:"organizationTypeId"`
-	CssConfigurationID       *int             `json:"cssConfigurationId"`
-	Deleted                  bool             `json:"deleted"`
-	EnableGoogleCalendar     bool             `json:"enableGoogleCalendar"`
-	ModifiedOn               string           `json:"modifiedOn"`
-	ShowFacebookWall         bool             `json:"showFacebookWall"`
-	ShowTwitterFeed          bool             `json:"showTwitterFeed"`
-	IsShownInPublicDirectory bool             `json:"isShownInPublicDirectory"`
-	IsAdminOnly              bool             `json:"isAdminOnly"`
-	IsBranch                 bool             `json:"isBranch"`
-	LegacyKey                interface{}      `json:"legacyKey"`
-	ParentLegacyKey          interface{}      `json:"parentLegacyKey"`
-	LegacyPrimaryContactKey  interface{}      `json:"legacyPrimaryContactKey"` 
+	Email                    string      `json:"email"`
+	CommunityID              int         `json:"communityId"`
+	NameSortKey              string      `json:"nameSortKey"`
+	Comment                  *string     `json:"comment"`
+	ShowJoin                 bool        `json:"showJoin"`
+	StatusChangeDateTime     string      `json:"statusChangeDateTime"`
+	StartDate                *string     `json:"startDate"`
+	EndDate                  *string     `json:"endDate"`
+	ParentID                 *int        `json:"parentId"`
+	WallID                   *int        `json:"wallId"`
+	DiscussionID             *int        `json:"discussionId"`
+	GroupTypeID              *int        `json:"groupTypeId"`
+	OrganizationTypeID       int         `json:"organizationTypeId"`
+	CssConfigurationID       *int        `json:"cssConfigurationId"`
+	Deleted                  bool        `json:"deleted"`
+	EnableGoogleCalendar     bool        `json:"enableGoogleCalendar"`
+	ModifiedOn               string      `json:"modifiedOn"`
+	ShowFacebookWall         bool        `json:"showFacebookWall"`
+	ShowTwitterFeed          bool        `json:"showTwitterFeed"`
+	IsShownInPublicDirectory bool        `json:"isShownInPublicDirectory"`
+	IsAdminOnly              bool        `json:"isAdminOnly"`
+	IsBranch                 bool        `json:"isBranch"`
+	LegacyKey                interface{} `json:"legacyKey"`
+	ParentLegacyKey          interface{} `json:"parentLegacyKey"`
+	LegacyPrimaryContactKey  interface{} `json:"legacyPrimaryContactKey"`
 
 	// Complex nested objects
-	SocialMedia       SocialMedia       `json:"socialMedia"`
-	PrimaryContact    PrimaryContact    `json:"primaryContact"`
-	PrimaryContactID  PrimaryContactID  `json:"primaryContactId"`
-	ContactInfo       []ContactInfo     `json:"contactInfo"`
-	OrganizationType  OrganizationType  `json:"organizationType"`
-	Categories        []Category        `json:"categories"`
-	Submissions       []interface{}     `json:"submissions"`
-	CoverPhoto        CoverPhoto        `json:"coverPhoto"`
-	
+	SocialMedia         SocialMedia          `json:"socialMedia"`
+	SocialMediaEnriched *SocialMediaEnriched `json:"socialMediaEnriched,omitempty"`
+	PrimaryContact      PrimaryContact       `json:"primaryContact"`
+	PrimaryContactID    PrimaryContactID     `json:"primaryContactId"`
+	ContactInfo         []ContactInfo        `json:"contactInfo"`
+	OrganizationType    OrganizationType     `json:"organizationType"`
+	Categories          []Category           `json:"categories"`
+	Submissions         []interface{}        `json:"submissions"`
+	CoverPhoto          CoverPhoto           `json:"coverPhoto"`
+
 	// Additional metadata
 	ImageServerBaseURL string `json:"imageServerBaseUrl"`
-	BaseURL           string `json:"baseUrl"`
+	BaseURL            string `json:"baseUrl"`
 }
 
 // APIResponse represents the structure of the API response
@@ -193,51 +204,280 @@ type DetailPageData struct {
 	} `json:"preFetchedData"`
 }
 
-// Progress tracks scraping progress for resume capability
+// Progress tracks scraping progress for resume capability. CompletedPages
+// and CompletedOrgs are sets, not slices, so isPageCompleted/isOrgCompleted
+// are O(1) instead of a linear scan; MarshalJSON/UnmarshalJSON render them
+// as sorted arrays on disk, so progress.json stays readable and old,
+// slice-shaped checkpoints still load.
 type Progress struct {
-	TotalOrgs      int    `json:"totalOrgs"`
-	ScrapedOrgs    int    `json:"scrapedOrgs"`
-	LastSkip       int    `json:"lastSkip"`
-	LastUpdated    string `json:"lastUpdated"`
-	CompletedPages []int  `json:"completedPages"`
-	CompletedOrgs  []string `json:"completedOrgs"`
+	TotalOrgs      int
+	ScrapedOrgs    int
+	LastSkip       int
+	LastUpdated    string
+	CompletedPages map[int]struct{}
+	CompletedOrgs  map[string]struct{}
+	// PageRetries counts retry attempts per page, keyed by page number.
+	// Unlike CompletedPages/CompletedOrgs it round-trips through
+	// encoding/json as-is: Go marshals int-keyed maps as JSON objects
+	// with sorted string keys, which is already a stable, readable
+	// on-disk shape.
+	PageRetries map[int]int
+}
+
+// progressJSON is Progress's on-disk representation.
+type progressJSON struct {
+	TotalOrgs      int         `json:"totalOrgs"`
+	ScrapedOrgs    int         `json:"scrapedOrgs"`
+	LastSkip       int         `json:"lastSkip"`
+	LastUpdated    string      `json:"lastUpdated"`
+	CompletedPages []int       `json:"completedPages"`
+	CompletedOrgs  []string    `json:"completedOrgs"`
+	PageRetries    map[int]int `json:"pageRetries,omitempty"`
+}
+
+func (p Progress) MarshalJSON() ([]byte, error) {
+	pages := make([]int, 0, len(p.CompletedPages))
+	for page := range p.CompletedPages {
+		pages = append(pages, page)
+	}
+	sort.Ints(pages)
+
+	orgs := make([]string, 0, len(p.CompletedOrgs))
+	for org := range p.CompletedOrgs {
+		orgs = append(orgs, org)
+	}
+	sort.Strings(orgs)
+
+	return json.Marshal(progressJSON{
+		TotalOrgs:      p.TotalOrgs,
+		ScrapedOrgs:    p.ScrapedOrgs,
+		LastSkip:       p.LastSkip,
+		LastUpdated:    p.LastUpdated,
+		CompletedPages: pages,
+		CompletedOrgs:  orgs,
+		PageRetries:    p.PageRetries,
+	})
+}
+
+func (p *Progress) UnmarshalJSON(data []byte) error {
+	var pj progressJSON
+	if err := json.Unmarshal(data, &pj); err != nil {
+		return err
+	}
+
+	p.TotalOrgs = pj.TotalOrgs
+	p.ScrapedOrgs = pj.ScrapedOrgs
+	p.LastSkip = pj.LastSkip
+	p.LastUpdated = pj.LastUpdated
+
+	p.CompletedPages = make(map[int]struct{}, len(pj.CompletedPages))
+	for _, page := range pj.CompletedPages {
+		p.CompletedPages[page] = struct{}{}
+	}
+	p.CompletedOrgs = make(map[string]struct{}, len(pj.CompletedOrgs))
+	for _, org := range pj.CompletedOrgs {
+		p.CompletedOrgs[org] = struct{}{}
+	}
+	p.PageRetries = pj.PageRetries
+	return nil
 }
 
 // ScraperConfig holds configuration for the scraper
 type ScraperConfig struct {
-	Workers    int
-	PageSize   int
-	Resume     bool
-	BaseURL    string
-	UserAgent  string
-	MaxRetries int
-	RetryDelay time.Duration
+	Workers      int
+	PageSize     int
+	Resume       bool
+	BaseURL      string
+	UserAgent    string
+	MaxRetries   int
+	RetryDelay   time.Duration
+	StoreDSN     string
+	DataDir      string
+	RPS          float64
+	Burst        int
+	MaxBackoff   time.Duration
+	ProcessMedia bool
+	MediaStore   string
+	Incremental  bool
+	EnrichSocial bool
+	OutputFormat string
+	OutputPath   string
 }
 
 // Scraper manages the scraping process
 type Scraper struct {
-	config   ScraperConfig
-	client   *http.Client
-	progress Progress
-	mu       sync.Mutex
+	config           ScraperConfig
+	client           *http.Client
+	store            Store
+	limiter          *HostLimiter
+	fetchPolicy      FetchPolicy
+	media            *MediaPipeline
+	changeLog        *ChangeLog
+	social           *SocialEnricher
+	output           OutputSink
+	progressFunc     ProgressFunc
+	progressPath     string
+	autosaveInterval time.Duration
+	autosaveStop     chan struct{}
+	autosaveDone     chan struct{}
+	progress         Progress
+	mu               sync.Mutex
+	cancel           context.CancelFunc
+}
+
+// defaultAutosaveInterval is how often the background autosave goroutine
+// checkpoints progress to disk when WithAutosaveInterval isn't used.
+const defaultAutosaveInterval = 30 * time.Second
+
+// ProgressEventKind identifies the stage of the scrape a ProgressEvent
+// describes.
+type ProgressEventKind string
+
+const (
+	PageStarted   ProgressEventKind = "page_started"
+	PageCompleted ProgressEventKind = "page_completed"
+	OrgStarted    ProgressEventKind = "org_started"
+	OrgCompleted  ProgressEventKind = "org_completed"
+	OrgFailed     ProgressEventKind = "org_failed"
+	Retried       ProgressEventKind = "retried"
+)
+
+// ProgressEvent describes one step of progress during a scrape: a listing
+// page fetched, or a single organization enriched. Current/Total let a
+// consumer render a percentage or ETA without tracking state itself.
+type ProgressEvent struct {
+	Kind    ProgressEventKind
+	Page    int
+	OrgID   string
+	Current int
+	Total   int
+	Err     error
+}
+
+// ProgressFunc receives every ProgressEvent emitted during a scrape. It's
+// how callers plug in a TTY progress bar, a Prometheus counter, or a
+// webhook notifier without forking the scraper.
+type ProgressFunc func(event ProgressEvent) error
+
+// ErrStopProgress, returned from a ProgressFunc, gracefully stops the
+// scrape: in-flight workers drain and progress is flushed before Run
+// returns, the same shutdown path a SIGINT takes.
+var ErrStopProgress = errors.New("progressfunc: stop scrape")
+
+// ScraperOption customizes a Scraper built by NewScraper.
+type ScraperOption func(*Scraper)
+
+// WithProgressFunc registers fn to be invoked for every ProgressEvent.
+func WithProgressFunc(fn ProgressFunc) ScraperOption {
+	return func(s *Scraper) {
+		s.progressFunc = fn
+	}
+}
+
+// WithProgressPath overrides the filesystem store's default progress.json
+// location (data/../progress.json).
+func WithProgressPath(path string) ScraperOption {
+	return func(s *Scraper) {
+		s.progressPath = path
+	}
+}
+
+// WithAutosaveInterval overrides how often the background autosave
+// goroutine checkpoints progress to disk (default 30s). Pass 0 to disable
+// the autosave goroutine entirely; progress is still saved every 10
+// completed orgs and once at the end of Run.
+func WithAutosaveInterval(d time.Duration) ScraperOption {
+	return func(s *Scraper) {
+		s.autosaveInterval = d
+	}
+}
+
+// WithTransport overrides the scraper's http.Client's Transport, e.g. to
+// point it at a test server or inject request/response logging.
+func WithTransport(rt http.RoundTripper) ScraperOption {
+	return func(s *Scraper) {
+		s.client.Transport = rt
+	}
+}
+
+// WithFetchPolicy overrides the default rate limit/retry/backoff policy
+// (built from ScraperConfig's RPS/Burst/MaxRetries/RetryDelay/MaxBackoff
+// fields) that fetchOrganizations/enrichOrganization retry against.
+func WithFetchPolicy(policy FetchPolicy) ScraperOption {
+	return func(s *Scraper) {
+		s.fetchPolicy = policy
+	}
+}
+
+// emitProgress invokes the configured ProgressFunc, if any, synchronously
+// on the calling goroutine, so a slow callback slows the scrape down. An
+// error other than ErrStopProgress is logged and otherwise ignored, so a
+// failing callback can't take down the scrape. ErrStopProgress cancels the
+// run's context.
+func (s *Scraper) emitProgress(event ProgressEvent) {
+	if s.progressFunc == nil {
+		return
+	}
+	if err := s.progressFunc(event); err != nil {
+		if errors.Is(err, ErrStopProgress) {
+			log.Println("ProgressFunc requested stop; finishing in-flight work and saving progress...")
+			if s.cancel != nil {
+				s.cancel()
+			}
+			return
+		}
+		log.Printf("ProgressFunc returned error (ignored): %v", err)
+	}
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		if err := serveCmd(os.Args[2:]); err != nil {
+			log.Fatalf("Serve failed: %v", err)
+		}
+		return
+	}
+
 	var workers = flag.Int("workers", 10, "Number of concurrent workers")
 	var resume = flag.Bool("resume", false, "Resume from last checkpoint")
+	var store = flag.String("store", "", "Storage backend DSN, e.g. sqlite://data/callink.db or postgres://... (default: one JSON file per org under data/)")
+	var rps = flag.Float64("rps", 5, "Max requests/sec per host")
+	var burst = flag.Int("burst", 5, "Burst size per host for the rate limiter")
+	var maxBackoff = flag.Duration("max-backoff", 2*time.Minute, "Maximum retry/cool-off backoff per host")
+	var processMedia = flag.Bool("process-media", false, "Download, hash and re-host profile/cover images")
+	var mediaStore = flag.String("media-store", "", "Media uploader DSN: s3://bucket/prefix, bunny://zone/prefix (default: local dir under data/media)")
+	var incremental = flag.Bool("incremental", false, "Skip orgs unchanged since the last run and write a per-run JSON Patch changelog")
+	var enrichSocial = flag.Bool("enrich-social", false, "Normalize, verify and enrich SocialMedia links")
+	var outputFormat = flag.String("output-format", "", "Additional structured output sink: dir-json, jsonl, yaml, csv, sqlite (default: none; --store already persists every org)")
+	var outputPath = flag.String("output-path", "", "Destination for --output-format; meaning (file or directory) depends on the format (default: data/organizations.<ext>, or data/output for dir-json)")
 	flag.Parse()
 
 	config := ScraperConfig{
-		Workers:    *workers,
-		PageSize:   100,
-		Resume:     *resume,
-		BaseURL:    "https://callink.berkeley.edu/api/discovery/search/organizations",
-		UserAgent:  "Mozilla/5.0 (X11; Linux x86_64; rv:143.0) Gecko/20100101 Firefox/143.0",
-		MaxRetries: 3,
-		RetryDelay: time.Second * 2,
+		Workers:      *workers,
+		PageSize:     100,
+		Resume:       *resume,
+		BaseURL:      "https://callink.berkeley.edu/api/discovery/search/organizations",
+		UserAgent:    "Mozilla/5.0 (X11; Linux x86_64; rv:143.0) Gecko/20100101 Firefox/143.0",
+		MaxRetries:   3,
+		RetryDelay:   time.Second * 2,
+		StoreDSN:     *store,
+		DataDir:      "data",
+		RPS:          *rps,
+		Burst:        *burst,
+		MaxBackoff:   *maxBackoff,
+		ProcessMedia: *processMedia,
+		MediaStore:   *mediaStore,
+		Incremental:  *incremental,
+		EnrichSocial: *enrichSocial,
+		OutputFormat: *outputFormat,
+		OutputPath:   *outputPath,
 	}
 
-	scraper := NewScraper(config)
+	scraper, err := NewScraper(config)
+	if err != nil {
+		log.Fatalf("Failed to initialize scraper: %v", err)
+	}
+	defer scraper.store.Close()
 
 	log.Printf("Starting Berkeley CalLink enhanced scraper with %d workers", config.Workers)
 
@@ -248,8 +488,11 @@ func main() {
 	log.Println("Enhanced scraping completed successfully!")
 }
 
-// NewScraper creates a new scraper instance
-func NewScraper(config ScraperConfig) *Scraper {
+// NewScraper creates a new scraper instance. By default no ProgressFunc is
+// registered (progress shows only as log lines and the TTY progress bar)
+// and progress is persisted to the default progress.json location; pass
+// WithProgressFunc/WithProgressPath to customize either.
+func NewScraper(config ScraperConfig, opts ...ScraperOption) (*Scraper, error) {
 	client := &http.Client{
 		Timeout: 30 * time.Second,
 		Transport: &http.Transport{
@@ -259,23 +502,89 @@ func NewScraper(config ScraperConfig) *Scraper {
 		},
 	}
 
-	return &Scraper{
-		config: config,
-		client: client,
+	var media *MediaPipeline
+	if config.ProcessMedia {
+		uploader, err := NewMediaUploader(config.MediaStore)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init media uploader: %w", err)
+		}
+		media = NewMediaPipeline(client, uploader)
+		if err := media.LoadManifest(); err != nil {
+			log.Printf("Warning: failed to load media manifest: %v", err)
+		}
+	}
+
+	var output OutputSink
+	if config.OutputFormat != "" {
+		sink, err := NewOutputSink(config.OutputFormat, config.OutputPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init output sink: %w", err)
+		}
+		output = sink
+	}
+
+	s := &Scraper{
+		config:           config,
+		client:           client,
+		fetchPolicy:      DefaultFetchPolicy(config.RPS, config.Burst, config.MaxRetries, config.RetryDelay, config.MaxBackoff),
+		media:            media,
+		output:           output,
+		autosaveInterval: defaultAutosaveInterval,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// limiter/social are built from the final fetchPolicy/client, so
+	// WithFetchPolicy/WithTransport (applied above) actually take effect.
+	s.limiter = NewHostLimiter(float64(s.fetchPolicy.RateLimit), s.fetchPolicy.Burst, s.fetchPolicy.MaxBackoff)
+	if config.EnrichSocial {
+		s.social = NewSocialEnricher(s.client, s.limiter)
 	}
+
+	store, err := NewStore(config.StoreDSN, config.DataDir, s.progressPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	s.store = store
+
+	return s, nil
 }
 
-// Run executes the enhanced scraping process
+// Run executes the enhanced scraping process. It installs a SIGINT/SIGTERM
+// handler: the first signal cancels the context passed down to the
+// workers and fetches, aborting in-flight requests, draining the workers
+// and flushing progress before exiting; a second signal force-quits
+// immediately.
 func (s *Scraper) Run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		log.Println("Received interrupt, finishing in-flight work and saving progress (press again to force quit)...")
+		cancel()
+		<-sigCh
+		log.Println("Received second interrupt, forcing exit")
+		os.Exit(1)
+	}()
+	defer signal.Stop(sigCh)
+
 	// Load or initialize progress
 	if err := s.loadProgress(); err != nil && !s.config.Resume {
 		log.Printf("Could not load progress, starting fresh: %v", err)
 		s.progress = Progress{
-			CompletedPages: make([]int, 0),
-			CompletedOrgs:  make([]string, 0),
+			CompletedPages: make(map[int]struct{}),
+			CompletedOrgs:  make(map[string]struct{}),
 		}
 	}
 
+	s.startAutosave()
+	defer s.Close()
+
 	// Get total count first
 	if s.progress.TotalOrgs == 0 {
 		totalOrgs, err := s.getTotalCount()
@@ -288,13 +597,54 @@ func (s *Scraper) Run() error {
 
 	// Get all organizations from API first
 	log.Println("Fetching organization list from API...")
-	allOrgs, err := s.fetchAllOrganizations()
+	allOrgs, err := s.fetchAllOrganizations(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to fetch organizations: %w", err)
 	}
 
 	log.Printf("Fetched %d organizations from API, now enriching with detail pages...", len(allOrgs))
 
+	var listingHashes map[string]string
+	if s.config.Incremental {
+		// After an interrupt allOrgs is only a partial listing, so orgs
+		// missing from it have not disappeared from the API.
+		if ctx.Err() == nil {
+			if err := recordDeletedOrgs(s.store, s.config.DataDir, allOrgs); err != nil {
+				log.Printf("Warning: failed to update data/deleted.json: %v", err)
+			}
+		}
+
+		runID := time.Now().Format("20060102T150405")
+		changeLog, err := NewChangeLog(s.config.DataDir, runID)
+		if err != nil {
+			return fmt.Errorf("failed to open changelog: %w", err)
+		}
+		s.changeLog = changeLog
+		defer s.changeLog.Close()
+
+		listingHashes, err = loadListingHashes(s.config.DataDir)
+		if err != nil {
+			log.Printf("Warning: failed to load listing_hashes.json, treating all organizations as changed: %v", err)
+			listingHashes = make(map[string]string)
+		}
+
+		unchanged := 0
+		var filtered []Organization
+		for _, org := range allOrgs {
+			skip, err := shouldSkipUnchanged(listingHashes, org)
+			if err != nil {
+				log.Printf("Warning: incremental comparison failed for %v: %v", org.ID, err)
+			}
+			if skip {
+				unchanged++
+				continue
+			}
+			filtered = append(filtered, org)
+		}
+		log.Printf("Incremental: skipping %d unchanged organizations, enriching %d", unchanged, len(filtered))
+		allOrgs = filtered
+	}
+
 	// Create workers for detail page fetching
 	orgsChan := make(chan Organization, len(allOrgs))
 	resultsChan := make(chan Organization, len(allOrgs))
@@ -303,12 +653,18 @@ func (s *Scraper) Run() error {
 	// Start workers
 	for i := 0; i < s.config.Workers; i++ {
 		wg.Add(1)
-		go s.detailWorker(i, orgsChan, resultsChan, &wg)
+		go s.detailWorker(ctx, i, orgsChan, resultsChan, &wg)
 	}
 
 	// Queue organizations that haven't been completed
 	orgsQueued := 0
+queueLoop:
 	for _, org := range allOrgs {
+		select {
+		case <-ctx.Done():
+			break queueLoop
+		default:
+		}
 		if !s.isOrgCompleted(fmt.Sprintf("%v", org.ID)) {
 			orgsChan <- org
 			orgsQueued++
@@ -318,7 +674,8 @@ func (s *Scraper) Run() error {
 
 	log.Printf("Queued %d organizations for detail enrichment", orgsQueued)
 
-	// Start results collector
+	// Start results collector, with a live progress bar driven off the
+	// results channel instead of periodic log lines.
 	go s.collectDetailResults(resultsChan, orgsQueued)
 
 	// Wait for all workers to complete
@@ -328,6 +685,37 @@ func (s *Scraper) Run() error {
 	// Wait a bit for results collector to finish
 	time.Sleep(2 * time.Second)
 
+	// Flush progress before exiting, whether we ran to completion or were
+	// interrupted mid-run.
+	if err := s.saveProgress(); err != nil {
+		log.Printf("Warning: Failed to save progress: %v", err)
+	}
+
+	if s.media != nil {
+		if err := s.media.SaveManifest(); err != nil {
+			log.Printf("Warning: Failed to save media manifest: %v", err)
+		}
+	}
+
+	if s.output != nil {
+		if err := s.output.Close(); err != nil {
+			log.Printf("Warning: failed to close output sink: %v", err)
+		}
+	}
+
+	if ctx.Err() != nil {
+		log.Println("Scrape interrupted; progress saved for --resume")
+		return nil
+	}
+
+	// Only a run that got this far has enriched every changed org, so only
+	// now do its listing hashes become the baseline for the next run.
+	if listingHashes != nil {
+		if err := saveListingHashes(s.config.DataDir, listingHashes); err != nil {
+			log.Printf("Warning: failed to save listing_hashes.json: %v", err)
+		}
+	}
+
 	// Save final combined file
 	if err := s.saveCombinedFile(); err != nil {
 		log.Printf("Warning: Failed to save combined file: %v", err)
@@ -337,18 +725,26 @@ func (s *Scraper) Run() error {
 }
 
 // fetchAllOrganizations fetches all organizations from the API
-func (s *Scraper) fetchAllOrganizations() ([]Organization, error) {
+func (s *Scraper) fetchAllOrganizations(ctx context.Context) ([]Organization, error) {
 	totalPages := int(math.Ceil(float64(s.progress.TotalOrgs) / float64(s.config.PageSize)))
 	var allOrgs []Organization
 
 	for page := 0; page < totalPages; page++ {
+		if ctx.Err() != nil {
+			return allOrgs, nil
+		}
+
+		s.emitProgress(ProgressEvent{Kind: PageStarted, Page: page, Current: page, Total: totalPages})
+
 		skip := page * s.config.PageSize
-		orgs, err := s.fetchOrganizations(skip)
+		orgs, err := s.fetchOrganizations(ctx, skip)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch page %d: %w", page+1, err)
 		}
 		allOrgs = append(allOrgs, orgs...)
-		
+		s.markPageCompleted(page)
+		s.emitProgress(ProgressEvent{Kind: PageCompleted, Page: page, Current: page + 1, Total: totalPages})
+
 		if page%5 == 0 {
 			log.Printf("Fetched page %d/%d (%d orgs)", page+1, totalPages, len(allOrgs))
 		}
@@ -358,15 +754,38 @@ func (s *Scraper) fetchAllOrganizations() ([]Organization, error) {
 }
 
 // detailWorker processes organizations to fetch their detail pages
-func (s *Scraper) detailWorker(id int, orgs <-chan Organization, results chan<- Organization, wg *sync.WaitGroup) {
+func (s *Scraper) detailWorker(ctx context.Context, id int, orgs <-chan Organization, results chan<- Organization, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	for org := range orgs {
+		if ctx.Err() != nil {
+			results <- org
+			continue
+		}
+
 		log.Printf("Worker %d: Enriching org %v (%s)", id, org.ID, org.WebsiteKey)
 
-		enrichedOrg, err := s.enrichOrganization(org)
+		orgID := fmt.Sprintf("%v", org.ID)
+		s.mu.Lock()
+		current := s.progress.ScrapedOrgs
+		s.mu.Unlock()
+		s.emitProgress(ProgressEvent{Kind: OrgStarted, OrgID: orgID, Current: current, Total: s.progress.TotalOrgs})
+
+		enrichedOrg, err := s.enrichOrganization(ctx, org)
 		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				// Shutdown fired mid-enrichment: don't mark this org
+				// completed or count it as scraped, so a future run
+				// (or a resume) picks it up and does the full fetch
+				// instead of persisting half-scraped data as done.
+				log.Printf("Worker %d: canceled while enriching org %v; leaving for a future run", id, org.ID)
+				continue
+			}
 			log.Printf("Worker %d: Failed to enrich org %v: %v", id, org.ID, err)
+			s.mu.Lock()
+			current = s.progress.ScrapedOrgs
+			s.mu.Unlock()
+			s.emitProgress(ProgressEvent{Kind: OrgFailed, OrgID: orgID, Err: err, Current: current, Total: s.progress.TotalOrgs})
 			// Still save the basic org data
 			enrichedOrg = org
 		}
@@ -374,26 +793,28 @@ func (s *Scraper) detailWorker(id int, orgs <-chan Organization, results chan<-
 		results <- enrichedOrg
 
 		// Mark org as completed
-		s.markOrgCompleted(fmt.Sprintf("%v", org.ID))
+		s.markOrgCompleted(orgID)
 
 		// Update progress
 		s.mu.Lock()
 		s.progress.ScrapedOrgs++
 		s.progress.LastUpdated = time.Now().Format(time.RFC3339)
+		scraped := s.progress.ScrapedOrgs
 		s.mu.Unlock()
 
+		if err == nil {
+			s.emitProgress(ProgressEvent{Kind: OrgCompleted, OrgID: orgID, Current: scraped, Total: s.progress.TotalOrgs})
+		}
+
 		// Save progress periodically
-		if s.progress.ScrapedOrgs%10 == 0 {
+		if scraped%10 == 0 {
 			s.saveProgress()
 		}
-
-		// Rate limiting
-		time.Sleep(200 * time.Millisecond)
 	}
 }
 
 // enrichOrganization fetches detail page data and merges it with API data
-func (s *Scraper) enrichOrganization(org Organization) (Organization, error) {
+func (s *Scraper) enrichOrganization(ctx context.Context, org Organization) (Organization, error) {
 	if org.WebsiteKey == "" {
 		return org, fmt.Errorf("no websiteKey available")
 	}
@@ -401,15 +822,26 @@ func (s *Scraper) enrichOrganization(org Organization) (Organization, error) {
 	url := fmt.Sprintf("https://callink.berkeley.edu/organization/%s", org.WebsiteKey)
 
 	var detailOrg Organization
+	retryOn := s.fetchPolicy.RetryOn
+	if retryOn == nil {
+		retryOn = DefaultRetryOn
+	}
+	orgID := fmt.Sprintf("%v", org.ID)
 
 	// Retry logic
-	for attempt := 0; attempt <= s.config.MaxRetries; attempt++ {
+	for attempt := 0; attempt <= s.fetchPolicy.MaxRetries; attempt++ {
+		if ctx.Err() != nil {
+			return org, ctx.Err()
+		}
 		if attempt > 0 {
-			waitTime := time.Duration(attempt) * s.config.RetryDelay
-			time.Sleep(waitTime)
+			s.emitProgress(ProgressEvent{Kind: Retried, OrgID: orgID, Current: attempt, Total: s.fetchPolicy.MaxRetries})
+			time.Sleep(BackoffDelay(attempt-1, s.fetchPolicy.BaseBackoff, s.fetchPolicy.MaxBackoff))
+		}
+		if err := s.limiter.Wait(ctx, url); err != nil {
+			return org, err
 		}
 
-		req, err := http.NewRequest("GET", url, nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			continue
 		}
@@ -419,33 +851,39 @@ func (s *Scraper) enrichOrganization(org Organization) (Organization, error) {
 
 		resp, err := s.client.Do(req)
 		if err != nil {
-			if attempt == s.config.MaxRetries {
-				return org, fmt.Errorf("HTTP request failed after %d attempts: %w", s.config.MaxRetries+1, err)
+			if attempt == s.fetchPolicy.MaxRetries || !retryOn(nil, err) {
+				return org, fmt.Errorf("HTTP request failed after %d attempts: %w", attempt+1, err)
 			}
 			continue
 		}
 
+		if retryOn(resp, nil) {
+			s.limiter.ReportFailure(url, resp)
+		}
+
 		body, err := io.ReadAll(resp.Body)
 		resp.Body.Close()
 
 		if err != nil {
-			if attempt == s.config.MaxRetries {
+			if attempt == s.fetchPolicy.MaxRetries {
 				return org, fmt.Errorf("failed to read response body: %w", err)
 			}
 			continue
 		}
 
 		if resp.StatusCode != 200 {
-			if attempt == s.config.MaxRetries {
+			if attempt == s.fetchPolicy.MaxRetries || !retryOn(resp, nil) {
 				return org, fmt.Errorf("detail page returned status %d", resp.StatusCode)
 			}
 			continue
 		}
 
+		s.limiter.ReportSuccess(url)
+
 		// Extract and parse the JavaScript data
 		detailOrg, err = s.parseDetailPage(string(body))
 		if err != nil {
-			if attempt == s.config.MaxRetries {
+			if attempt == s.fetchPolicy.MaxRetries {
 				return org, fmt.Errorf("failed to parse detail page: %w", err)
 			}
 			continue
@@ -457,6 +895,16 @@ func (s *Scraper) enrichOrganization(org Organization) (Organization, error) {
 
 	// Merge API data with detail page data
 	mergedOrg := s.mergeOrganizationData(org, detailOrg)
+
+	if s.media != nil {
+		mergedOrg = s.media.Process(ctx, mergedOrg)
+	}
+
+	if s.social != nil {
+		enriched := s.social.Enrich(ctx, &mergedOrg.SocialMedia)
+		mergedOrg.SocialMediaEnriched = &enriched
+	}
+
 	return mergedOrg, nil
 }
 
@@ -465,7 +913,7 @@ func (s *Scraper) parseDetailPage(html string) (Organization, error) {
 	// Extract the JavaScript object using regex
 	re := regexp.MustCompile(`window\.initialAppState\s*=\s*({.*?});`)
 	matches := re.FindStringSubmatch(html)
-	
+
 	if len(matches) < 2 {
 		return Organization{}, fmt.Errorf("could not find initialAppState in HTML")
 	}
@@ -476,7 +924,7 @@ func (s *Scraper) parseDetailPage(html string) (Organization, error) {
 	}
 
 	org := pageData.PreFetchedData.Organization
-	
+
 	// Extract additional data from the full JSON structure
 	var fullData map[string]interface{}
 	if err := json.Unmarshal([]byte(matches[1]), &fullData); err == nil {
@@ -485,13 +933,13 @@ func (s *Scraper) parseDetailPage(html string) (Organization, error) {
 			if imageServerBaseURL, ok := prefetchedData["imageServerBaseUrl"].(string); ok {
 				org.ImageServerBaseURL = imageServerBaseURL
 			}
-			
+
 			// Extract cover photo data from organization
 			if orgData, ok := prefetchedData["organization"].(map[string]interface{}); ok {
 				if coverPhotoData, ok := orgData["coverPhoto"].(map[string]interface{}); ok {
 					org.CoverPhoto = extractCoverPhoto(coverPhotoData)
 				}
-				
+
 				// Extract primaryContactId data
 				if primaryContactIdData, ok := orgData["primaryContactId"].(map[string]interface{}); ok {
 					org.PrimaryContactID = extractPrimaryContactID(primaryContactIdData)
@@ -506,7 +954,7 @@ func (s *Scraper) parseDetailPage(html string) (Organization, error) {
 // extractCoverPhoto extracts cover photo data from raw interface{} data
 func extractCoverPhoto(data map[string]interface{}) CoverPhoto {
 	coverPhoto := CoverPhoto{}
-	
+
 	if id, ok := data["id"].(float64); ok {
 		coverPhoto.ID = int(id)
 	}
@@ -531,14 +979,14 @@ func extractCoverPhoto(data map[string]interface{}) CoverPhoto {
 	if institutionId, ok := data["institutionId"].(float64); ok {
 		coverPhoto.InstitutionID = int(institutionId)
 	}
-	
+
 	return coverPhoto
 }
 
 // extractPrimaryContactID extracts primary contact ID data from raw interface{} data
 func extractPrimaryContactID(data map[string]interface{}) PrimaryContactID {
 	contactID := PrimaryContactID{}
-	
+
 	if communityMemberId, ok := data["communityMemberId"].(float64); ok {
 		contactID.CommunityMemberID = int(communityMemberId)
 	}
@@ -548,7 +996,7 @@ func extractPrimaryContactID(data map[string]interface{}) PrimaryContactID {
 	if campusEmail, ok := data["campusEmail"].(string); ok {
 		contactID.CampusEmail = campusEmail
 	}
-	
+
 	return contactID
 }
 
@@ -579,36 +1027,72 @@ func (s *Scraper) mergeOrganizationData(apiOrg, detailOrg Organization) Organiza
 	return merged
 }
 
-// collectDetailResults saves enriched organizations to files
+// collectDetailResults saves enriched organizations to files, driving a
+// live TTY progress bar (scraped/total, ETA, req/s) off the results
+// channel instead of periodic log lines.
 func (s *Scraper) collectDetailResults(results <-chan Organization, expectedOrgs int) {
+	bar := progressbar.NewOptions(expectedOrgs,
+		progressbar.OptionSetDescription("Enriching organizations"),
+		progressbar.OptionShowCount(),
+		progressbar.OptionShowIts(),
+		progressbar.OptionSetItsString("org"),
+		progressbar.OptionThrottle(100*time.Millisecond),
+	)
+
 	orgCount := 0
 	for org := range results {
+		if s.changeLog != nil {
+			if previous, ok, err := s.store.GetOrganization(fmt.Sprintf("%v", org.ID)); err == nil && ok {
+				if err := s.changeLog.Record(previous, org); err != nil {
+					log.Printf("Failed to record change for org %v: %v", org.ID, err)
+				}
+			}
+		}
+
 		if err := s.saveOrganization(org); err != nil {
 			log.Printf("Failed to save organization %v: %v", org.ID, err)
 		}
-		orgCount++
-		if orgCount%25 == 0 {
-			log.Printf("Saved %d/%d enriched organizations", orgCount, expectedOrgs)
+
+		if s.output != nil {
+			if err := s.output.Write(org); err != nil {
+				log.Printf("Failed to write organization %v to output sink: %v", org.ID, err)
+			}
 		}
+
+		orgCount++
+		bar.Add(1)
 	}
+	bar.Finish()
 	log.Printf("Results collector finished. Saved %d enriched organizations", orgCount)
 }
 
 // fetchOrganizations fetches a page of organizations from the API
-func (s *Scraper) fetchOrganizations(skip int) ([]Organization, error) {
+func (s *Scraper) fetchOrganizations(ctx context.Context, skip int) ([]Organization, error) {
 	url := fmt.Sprintf("%s?orderBy%%5B0%%5D=UpperName%%20asc&top=%d&filter=&query=&skip=%d",
 		s.config.BaseURL, s.config.PageSize, skip)
 
 	var resp APIResponse
+	retryOn := s.fetchPolicy.RetryOn
+	if retryOn == nil {
+		retryOn = DefaultRetryOn
+	}
 
 	// Retry logic
-	for attempt := 0; attempt <= s.config.MaxRetries; attempt++ {
+	for attempt := 0; attempt <= s.fetchPolicy.MaxRetries; attempt++ {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		if attempt > 0 {
-			waitTime := time.Duration(attempt) * s.config.RetryDelay
-			time.Sleep(waitTime)
+			page := skip / s.config.PageSize
+			s.recordPageRetry(page)
+			s.emitProgress(ProgressEvent{Kind: Retried, Page: page, Current: attempt, Total: s.fetchPolicy.MaxRetries})
+			time.Sleep(BackoffDelay(attempt-1, s.fetchPolicy.BaseBackoff, s.fetchPolicy.MaxBackoff))
+		}
+		if err := s.limiter.Wait(ctx, url); err != nil {
+			return nil, err
 		}
 
-		req, err := http.NewRequest("GET", url, nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			continue
 		}
@@ -618,31 +1102,37 @@ func (s *Scraper) fetchOrganizations(skip int) ([]Organization, error) {
 
 		httpResp, err := s.client.Do(req)
 		if err != nil {
-			if attempt == s.config.MaxRetries {
-				return nil, fmt.Errorf("HTTP request failed after %d attempts: %w", s.config.MaxRetries+1, err)
+			if attempt == s.fetchPolicy.MaxRetries || !retryOn(nil, err) {
+				return nil, fmt.Errorf("HTTP request failed after %d attempts: %w", attempt+1, err)
 			}
 			continue
 		}
 
+		if retryOn(httpResp, nil) {
+			s.limiter.ReportFailure(url, httpResp)
+		}
+
 		body, err := io.ReadAll(httpResp.Body)
 		httpResp.Body.Close()
 
 		if err != nil {
-			if attempt == s.config.MaxRetries {
+			if attempt == s.fetchPolicy.MaxRetries {
 				return nil, fmt.Errorf("failed to read response body: %w", err)
 			}
 			continue
 		}
 
 		if httpResp.StatusCode != 200 {
-			if attempt == s.config.MaxRetries {
+			if attempt == s.fetchPolicy.MaxRetries || !retryOn(httpResp, nil) {
 				return nil, fmt.Errorf("API returned status %d: %s", httpResp.StatusCode, string(body))
 			}
 			continue
 		}
 
+		s.limiter.ReportSuccess(url)
+
 		if err = json.Unmarshal(body, &resp); err != nil {
-			if attempt == s.config.MaxRetries {
+			if attempt == s.fetchPolicy.MaxRetries {
 				return nil, fmt.Errorf("failed to parse JSON response: %w", err)
 			}
 			continue
@@ -686,50 +1176,22 @@ func (s *Scraper) getTotalCount() (int, error) {
 	return apiResp.Count, nil
 }
 
-// saveOrganization saves an individual organization to a JSON file
+// saveOrganization saves an individual organization through the store
 func (s *Scraper) saveOrganization(org Organization) error {
-	filename := fmt.Sprintf("org_%v.json", org.ID)
-	if org.WebsiteKey != "" && org.WebsiteKey != "null" {
-		filename = fmt.Sprintf("org_%v_%s.json", org.ID, sanitizeFilename(org.WebsiteKey))
-	}
-
-	filepath := filepath.Join("data", filename)
-
-	data, err := json.MarshalIndent(org, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(filepath, data, 0644)
+	return s.store.UpsertOrganization(org)
 }
 
-// saveCombinedFile saves all organizations to a single combined file
+// saveCombinedFile saves all organizations to a single combined file.
+// With the SQL-backed stores this is just a dump of ListOrganizations;
+// it no longer re-globs and re-unmarshals data/org_*.json.
 func (s *Scraper) saveCombinedFile() error {
 	log.Println("Creating combined organizations file...")
 
-	files, err := filepath.Glob("data/org_*.json")
+	allOrgs, err := s.store.ListOrganizations()
 	if err != nil {
 		return err
 	}
 
-	var allOrgs []Organization
-
-	for _, file := range files {
-		data, err := os.ReadFile(file)
-		if err != nil {
-			log.Printf("Warning: Could not read %s: %v", file, err)
-			continue
-		}
-
-		var org Organization
-		if err := json.Unmarshal(data, &org); err != nil {
-			log.Printf("Warning: Could not parse %s: %v", file, err)
-			continue
-		}
-
-		allOrgs = append(allOrgs, org)
-	}
-
 	log.Printf("Combined %d organizations into single file", len(allOrgs))
 
 	combinedData, err := json.MarshalIndent(allOrgs, "", "  ")
@@ -737,7 +1199,7 @@ func (s *Scraper) saveCombinedFile() error {
 		return err
 	}
 
-	return os.WriteFile(filepath.Join("data", "all_organizations_detailed.json"), combinedData, 0644)
+	return os.WriteFile(filepath.Join(s.config.DataDir, "all_organizations_detailed.json"), combinedData, 0644)
 }
 
 // Progress tracking functions
@@ -746,87 +1208,174 @@ func (s *Scraper) loadProgress() error {
 		return fmt.Errorf("resume not enabled")
 	}
 
-	data, err := os.ReadFile("progress.json")
+	p, err := s.store.LoadProgress()
 	if err != nil {
 		return err
 	}
-
-	return json.Unmarshal(data, &s.progress)
+	s.progress = p
+	return nil
 }
 
+// saveProgress snapshots progress under s.mu (deep-copying the completion
+// sets, since a bare struct copy would still share their backing maps)
+// and hands the snapshot to the store, so JSON-encoding it can't race
+// against a worker mutating s.progress concurrently.
 func (s *Scraper) saveProgress() error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	snapshot := Progress{
+		TotalOrgs:      s.progress.TotalOrgs,
+		ScrapedOrgs:    s.progress.ScrapedOrgs,
+		LastSkip:       s.progress.LastSkip,
+		LastUpdated:    s.progress.LastUpdated,
+		CompletedPages: make(map[int]struct{}, len(s.progress.CompletedPages)),
+		CompletedOrgs:  make(map[string]struct{}, len(s.progress.CompletedOrgs)),
+		PageRetries:    make(map[int]int, len(s.progress.PageRetries)),
+	}
+	for page := range s.progress.CompletedPages {
+		snapshot.CompletedPages[page] = struct{}{}
+	}
+	for org := range s.progress.CompletedOrgs {
+		snapshot.CompletedOrgs[org] = struct{}{}
+	}
+	for page, n := range s.progress.PageRetries {
+		snapshot.PageRetries[page] = n
+	}
+	s.mu.Unlock()
 
-	data, err := json.MarshalIndent(s.progress, "", "  ")
-	if err != nil {
-		return err
+	return s.store.SaveProgress(snapshot)
+}
+
+// startAutosave launches a goroutine that periodically checkpoints
+// progress to disk, independent of the every-10-orgs save in detailWorker.
+// A no-op if autosaveInterval <= 0. Stopped by Close.
+func (s *Scraper) startAutosave() {
+	if s.autosaveInterval <= 0 {
+		return
 	}
 
-	return os.WriteFile("progress.json", data, 0644)
+	s.autosaveStop = make(chan struct{})
+	s.autosaveDone = make(chan struct{})
+	go func() {
+		defer close(s.autosaveDone)
+
+		ticker := time.NewTicker(s.autosaveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.saveProgress(); err != nil {
+					log.Printf("Autosave: failed to save progress: %v", err)
+				}
+			case <-s.autosaveStop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the autosave goroutine, if one is running, and waits for it
+// to exit. Safe to call even if Run was never called.
+func (s *Scraper) Close() error {
+	if s.autosaveStop != nil {
+		close(s.autosaveStop)
+		<-s.autosaveDone
+		s.autosaveStop = nil
+	}
+	return nil
 }
 
 func (s *Scraper) isPageCompleted(page int) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, completedPage := range s.progress.CompletedPages {
-		if completedPage == page {
-			return true
-		}
-	}
-	return false
+	_, ok := s.progress.CompletedPages[page]
+	return ok
 }
 
 func (s *Scraper) markPageCompleted(page int) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, completedPage := range s.progress.CompletedPages {
-		if completedPage == page {
-			return
-		}
+	if s.progress.CompletedPages == nil {
+		s.progress.CompletedPages = make(map[int]struct{})
 	}
+	s.progress.CompletedPages[page] = struct{}{}
+}
 
-	s.progress.CompletedPages = append(s.progress.CompletedPages, page)
+// recordPageRetry increments the retry counter for page, persisted in
+// progress.json under PageRetries.
+func (s *Scraper) recordPageRetry(page int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.progress.PageRetries == nil {
+		s.progress.PageRetries = make(map[int]int)
+	}
+	s.progress.PageRetries[page]++
 }
 
 func (s *Scraper) isOrgCompleted(orgID string) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, completedOrg := range s.progress.CompletedOrgs {
-		if completedOrg == orgID {
-			return true
-		}
-	}
-	return false
+	_, ok := s.progress.CompletedOrgs[orgID]
+	return ok
 }
 
 func (s *Scraper) markOrgCompleted(orgID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, completedOrg := range s.progress.CompletedOrgs {
-		if completedOrg == orgID {
-			return
-		}
+	if s.progress.CompletedOrgs == nil {
+		s.progress.CompletedOrgs = make(map[string]struct{})
 	}
+	s.progress.CompletedOrgs[orgID] = struct{}{}
+}
 
-	s.progress.CompletedOrgs = append(s.progress.CompletedOrgs, orgID)
+// maxFilenameRunes is the rune (not byte) length SanitizeFilename
+// truncates to.
+const maxFilenameRunes = 50
+
+// reservedDeviceNames are Windows device names that can't be used as a
+// filename, regardless of case.
+var reservedDeviceNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
 }
 
-// sanitizeFilename removes or replaces invalid characters for filenames
-func sanitizeFilename(s string) string {
+// SanitizeFilename turns s into a string safe to use as a filename.
+// It normalizes to NFC, replaces characters invalid on common
+// filesystems, and suffixes Windows reserved device names (CON, PRN,
+// NUL, AUX, COM1-9, LPT1-9). When s is longer than maxFilenameRunes it's
+// cut on a rune boundary (never splitting a multi-byte codepoint) and
+// suffixed with the first 8 hex chars of SHA-256(s), so two long,
+// similar names don't collapse onto the same truncated filename.
+func SanitizeFilename(s string) string {
+	original := s
+	s = norm.NFC.String(s)
+
 	invalid := []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|", " "}
-	result := s
 	for _, char := range invalid {
-		result = strings.ReplaceAll(result, char, "_")
+		s = strings.ReplaceAll(s, char, "_")
+	}
+
+	if reservedDeviceNames[strings.ToUpper(s)] {
+		s += "_"
 	}
 
-	if len(result) > 50 {
-		result = result[:50]
+	runes := []rune(s)
+	if len(runes) <= maxFilenameRunes {
+		return s
 	}
 
-	return result
-}
\ No newline at end of file
+	sum := sha256.Sum256([]byte(original))
+	suffix := "_" + hex.EncodeToString(sum[:])[:8]
+	cut := maxFilenameRunes - len([]rune(suffix))
+	if cut < 0 {
+		cut = 0
+	}
+	return string(runes[:cut]) + suffix
+}
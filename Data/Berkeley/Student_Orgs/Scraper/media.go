@@ -0,0 +1,412 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// MediaAsset records what the media pipeline learned about a single
+// downloaded image: its content hash (for dedup/skip-if-unchanged),
+// dimensions, MIME type, size, and the source URL it was downloaded from.
+type MediaAsset struct {
+	URL          string `json:"url"`
+	SHA256       string `json:"sha256"`
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+	MIME         string `json:"mime"`
+	Bytes        int64  `json:"bytes"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// mediaSourceCache records the validator headers (ETag/Last-Modified)
+// seen on a source URL's last successful download, plus where it ended
+// up, so processOne can confirm via a cheap HEAD that the image hasn't
+// changed without paying for a full GET.
+type mediaSourceCache struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	UploadedURL  string `json:"uploadedUrl"`
+}
+
+// MediaUploader stores a local media file somewhere (local dir, S3, Bunny
+// CDN) and returns the public URL consumers should use going forward.
+type MediaUploader interface {
+	Upload(ctx context.Context, localPath, destPath, mime string) (url string, err error)
+}
+
+// NewMediaUploader parses a --media-store DSN:
+//
+//	(empty)              -> LocalDirUploader rooted at data/media
+//	s3://bucket/prefix    -> S3Uploader
+//	bunny://zone/prefix   -> BunnyUploader (reads BUNNY_ACCESS_KEY)
+func NewMediaUploader(dsn string) (MediaUploader, error) {
+	if dsn == "" {
+		return &LocalDirUploader{baseDir: filepath.Join("data", "media")}, nil
+	}
+
+	scheme, rest, ok := strings.Cut(dsn, "://")
+	if !ok {
+		return nil, fmt.Errorf("invalid --media-store DSN %q: missing scheme", dsn)
+	}
+
+	switch scheme {
+	case "s3":
+		bucket, prefix, _ := strings.Cut(rest, "/")
+		cfg, err := config.LoadDefaultConfig(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		}
+		return &S3Uploader{client: s3.NewFromConfig(cfg), bucket: bucket, prefix: prefix}, nil
+	case "bunny":
+		zone, prefix, _ := strings.Cut(rest, "/")
+		accessKey := os.Getenv("BUNNY_ACCESS_KEY")
+		if accessKey == "" {
+			return nil, fmt.Errorf("BUNNY_ACCESS_KEY must be set to use --media-store=bunny://...")
+		}
+		return &BunnyUploader{zone: zone, prefix: prefix, accessKey: accessKey, client: http.DefaultClient}, nil
+	default:
+		return nil, fmt.Errorf("unsupported media store scheme %q", scheme)
+	}
+}
+
+// LocalDirUploader is the default: it just copies the already-downloaded
+// file into baseDir/destPath and returns a file:// URL.
+type LocalDirUploader struct {
+	baseDir string
+}
+
+func (u *LocalDirUploader) Upload(_ context.Context, localPath, destPath, _ string) (string, error) {
+	target := filepath.Join(u.baseDir, destPath)
+	if target == localPath {
+		return "file://" + target, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		return "", err
+	}
+	return "file://" + target, nil
+}
+
+// S3Uploader puts media objects into an S3-compatible bucket.
+type S3Uploader struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+func (u *S3Uploader) Upload(ctx context.Context, localPath, destPath, mime string) (string, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	key := destPath
+	if u.prefix != "" {
+		key = u.prefix + "/" + destPath
+	}
+
+	_, err = u.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(u.bucket),
+		Key:         aws.String(key),
+		Body:        f,
+		ContentType: aws.String(mime),
+	})
+	if err != nil {
+		return "", fmt.Errorf("s3 upload failed: %w", err)
+	}
+	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", u.bucket, key), nil
+}
+
+// BunnyUploader PUTs media objects into a Bunny CDN Storage Zone.
+type BunnyUploader struct {
+	zone      string
+	prefix    string
+	accessKey string
+	client    *http.Client
+}
+
+func (u *BunnyUploader) Upload(ctx context.Context, localPath, destPath, mime string) (string, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	path := destPath
+	if u.prefix != "" {
+		path = u.prefix + "/" + destPath
+	}
+	uploadURL := fmt.Sprintf("https://storage.bunnycdn.com/%s/%s", u.zone, path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, f)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("AccessKey", u.accessKey)
+	req.Header.Set("Content-Type", mime)
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("bunny upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("bunny upload returned status %d", resp.StatusCode)
+	}
+
+	return fmt.Sprintf("https://%s.b-cdn.net/%s", u.zone, path), nil
+}
+
+// MediaPipeline downloads an organization's profile/cover images, hashes
+// them, uploads them through a MediaUploader, and rewrites the corresponding
+// URL fields to point at the uploaded copy.
+type MediaPipeline struct {
+	client      *http.Client
+	uploader    MediaUploader
+	seen        sync.Map // sha256 -> uploaded URL, so re-runs skip unchanged content
+	bySourceURL sync.Map // rawURL -> mediaSourceCache, so re-runs skip re-downloading unchanged content
+}
+
+// NewMediaPipeline builds a pipeline backed by the given uploader.
+func NewMediaPipeline(client *http.Client, uploader MediaUploader) *MediaPipeline {
+	return &MediaPipeline{client: client, uploader: uploader}
+}
+
+// Process downloads and uploads every image URL on org, returning a copy
+// with the URL fields rewritten to the uploaded location.
+func (m *MediaPipeline) Process(ctx context.Context, org Organization) Organization {
+	org.ProfilePictureURL = m.processOne(ctx, org.ProfilePictureURL)
+	org.CoverPhoto.URL = m.processOne(ctx, org.CoverPhoto.URL)
+	org.CoverPhoto.ThumbnailURL = m.processOne(ctx, org.CoverPhoto.ThumbnailURL)
+	return org
+}
+
+func (m *MediaPipeline) processOne(ctx context.Context, rawURL string) string {
+	if rawURL == "" {
+		return rawURL
+	}
+
+	if cached, ok := m.checkSourceUnchanged(ctx, rawURL); ok {
+		return cached
+	}
+
+	asset, localPath, err := m.download(ctx, rawURL)
+	if err != nil {
+		log.Printf("Media pipeline: failed to download %s: %v", rawURL, err)
+		return rawURL
+	}
+	defer os.Remove(localPath)
+
+	if cached, ok := m.seen.Load(asset.SHA256); ok {
+		url := cached.(string)
+		m.bySourceURL.Store(rawURL, mediaSourceCache{ETag: asset.ETag, LastModified: asset.LastModified, UploadedURL: url})
+		return url
+	}
+
+	var ext string
+	if u, err := url.Parse(rawURL); err == nil {
+		ext = path.Ext(u.Path)
+	}
+	destPath := fmt.Sprintf("%s/%s%s", asset.SHA256[:2], asset.SHA256, ext)
+
+	url, err := m.uploader.Upload(ctx, localPath, destPath, asset.MIME)
+	if err != nil {
+		log.Printf("Media pipeline: failed to upload %s: %v", rawURL, err)
+		return rawURL
+	}
+
+	m.seen.Store(asset.SHA256, url)
+	m.bySourceURL.Store(rawURL, mediaSourceCache{ETag: asset.ETag, LastModified: asset.LastModified, UploadedURL: url})
+	return url
+}
+
+// checkSourceUnchanged does a cheap HEAD request on rawURL and compares
+// its ETag/Last-Modified against what was recorded the last time this
+// pipeline downloaded it. A match means the image is unchanged, so the
+// caller can reuse the previously uploaded URL without paying for a full
+// GET — the content-hash cache in processOne only skips the re-upload,
+// this is what makes re-runs cheap on bandwidth/time too.
+func (m *MediaPipeline) checkSourceUnchanged(ctx context.Context, rawURL string) (string, bool) {
+	v, ok := m.bySourceURL.Load(rawURL)
+	if !ok {
+		return "", false
+	}
+	cached := v.(mediaSourceCache)
+	if cached.ETag == "" && cached.LastModified == "" {
+		return "", false
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		return "", false
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", false
+	}
+	defer resp.Body.Close()
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		return cached.UploadedURL, cached.ETag != "" && etag == cached.ETag
+	}
+	if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+		return cached.UploadedURL, cached.LastModified != "" && lastModified == cached.LastModified
+	}
+	return "", false
+}
+
+// download fetches rawURL into a temp file under data/media and computes
+// its content hash, dimensions and MIME type.
+func (m *MediaPipeline) download(ctx context.Context, rawURL string) (MediaAsset, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return MediaAsset{}, "", err
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return MediaAsset{}, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return MediaAsset{}, "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	if err := os.MkdirAll(filepath.Join("data", "media", "tmp"), 0755); err != nil {
+		return MediaAsset{}, "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Join("data", "media", "tmp"), "dl-*")
+	if err != nil {
+		return MediaAsset{}, "", err
+	}
+	defer tmp.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), resp.Body)
+	if err != nil {
+		return MediaAsset{}, "", err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return MediaAsset{}, "", err
+	}
+	width, height := 0, 0
+	if cfg, _, err := image.DecodeConfig(tmp); err == nil {
+		width, height = cfg.Width, cfg.Height
+	}
+
+	mime := resp.Header.Get("Content-Type")
+	if mime == "" {
+		mime = "application/octet-stream"
+	}
+
+	asset := MediaAsset{
+		URL:          rawURL,
+		SHA256:       hex.EncodeToString(hasher.Sum(nil)),
+		Width:        width,
+		Height:       height,
+		MIME:         mime,
+		Bytes:        size,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	return asset, tmp.Name(), nil
+}
+
+// manifestPath returns where the pipeline persists seen content hashes so
+// `seen` survives across runs of the scraper.
+func manifestPath() string {
+	return filepath.Join("data", "media", "manifest.json")
+}
+
+// mediaManifestFile is the manifest's on-disk envelope. Older manifests
+// were a flat sha256->url map with no envelope; LoadManifest falls back
+// to reading those directly into ByHash.
+type mediaManifestFile struct {
+	ByHash   map[string]string           `json:"byHash"`
+	BySource map[string]mediaSourceCache `json:"bySource,omitempty"`
+}
+
+// LoadManifest restores previously uploaded content hashes and per-source
+// ETag/Last-Modified validators, so re-runs can skip both the upload and
+// (via checkSourceUnchanged) the download for media that hasn't changed.
+func (m *MediaPipeline) LoadManifest() error {
+	data, err := os.ReadFile(manifestPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var file mediaManifestFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	if file.ByHash == nil {
+		var flat map[string]string
+		if err := json.Unmarshal(data, &flat); err == nil {
+			file.ByHash = flat
+		}
+	}
+
+	for sha, url := range file.ByHash {
+		m.seen.Store(sha, url)
+	}
+	for rawURL, cache := range file.BySource {
+		m.bySourceURL.Store(rawURL, cache)
+	}
+	return nil
+}
+
+// SaveManifest persists the current set of uploaded content hashes and
+// per-source validators.
+func (m *MediaPipeline) SaveManifest() error {
+	byHash := make(map[string]string)
+	m.seen.Range(func(k, v interface{}) bool {
+		byHash[k.(string)] = v.(string)
+		return true
+	})
+	bySource := make(map[string]mediaSourceCache)
+	m.bySourceURL.Range(func(k, v interface{}) bool {
+		bySource[k.(string)] = v.(mediaSourceCache)
+		return true
+	})
+
+	data, err := json.MarshalIndent(mediaManifestFile{ByHash: byHash, BySource: bySource}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(manifestPath()), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath(), data, 0644)
+}
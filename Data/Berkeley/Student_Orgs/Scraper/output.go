@@ -0,0 +1,371 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OutputSink is a pluggable structured-output destination for enriched
+// organizations, selected via --output-format/--output-path. It's
+// additive to (not a replacement for) the resume-capable Store: a sink
+// is only opened when --output-format is set. Write must be safe for
+// concurrent calls from worker goroutines.
+type OutputSink interface {
+	Write(org Organization) error
+	Close() error
+}
+
+// NewOutputSink builds the sink named by format, writing to path (whose
+// meaning — file or directory — depends on the format; "" selects each
+// sink's own default location under data/). Supported formats:
+//
+//	dir-json -> one JSON file per org, same naming scheme Store's
+//	            FileStore uses (the original pre-Store layout)
+//	jsonl    -> one JSON object per line, single append-only file
+//	yaml     -> one YAML document per org, separated by "---"
+//	csv      -> flattened schema, single file
+//	sqlite   -> orgs + officers tables, CGO-free via modernc.org/sqlite
+func NewOutputSink(format, path string) (OutputSink, error) {
+	switch format {
+	case "dir-json":
+		return NewDirJSONSink(path), nil
+	case "jsonl":
+		return NewJSONLSink(path)
+	case "yaml":
+		return NewYAMLSink(path)
+	case "csv":
+		return NewCSVSink(path)
+	case "sqlite":
+		return NewSQLiteSink(path)
+	default:
+		return nil, fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// DirJSONSink writes one JSON file per organization, using the same
+// org_<id>_<websiteKey>.json naming Store's FileStore uses.
+type DirJSONSink struct {
+	dir string
+}
+
+// NewDirJSONSink returns a sink rooted at dir (default: data/output).
+func NewDirJSONSink(dir string) *DirJSONSink {
+	if dir == "" {
+		dir = filepath.Join("data", "output")
+	}
+	return &DirJSONSink{dir: dir}
+}
+
+func (d *DirJSONSink) Write(org Organization) error {
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("org_%v.json", org.ID)
+	if org.WebsiteKey != "" && org.WebsiteKey != "null" {
+		filename = fmt.Sprintf("org_%v_%s.json", org.ID, SanitizeFilename(org.WebsiteKey))
+	}
+
+	data, err := json.MarshalIndent(org, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(d.dir, filename), data, 0644)
+}
+
+func (d *DirJSONSink) Close() error { return nil }
+
+// JSONLSink appends one JSON object per line to a single file. Unlike
+// DirJSONSink, this needs no per-org filenames (and so no
+// SanitizeFilename truncation at all) and keeps tens of thousands of
+// records out of a directory listing — and streams
+// straight into tools like BigQuery or Spark that read newline-delimited
+// JSON natively.
+type JSONLSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewJSONLSink opens (creating and appending to) path (default:
+// data/organizations.jsonl).
+func NewJSONLSink(path string) (*JSONLSink, error) {
+	if path == "" {
+		path = filepath.Join("data", "organizations.jsonl")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLSink{file: f}, nil
+}
+
+func (j *JSONLSink) Write(org Organization) error {
+	data, err := json.Marshal(org)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.file.Write(append(data, '\n'))
+	return err
+}
+
+func (j *JSONLSink) Close() error {
+	return j.file.Close()
+}
+
+// YAMLSink appends one YAML document per org to a single file, documents
+// separated by a "---" marker.
+type YAMLSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewYAMLSink opens (creating and appending to) path (default:
+// data/organizations.yaml).
+func NewYAMLSink(path string) (*YAMLSink, error) {
+	if path == "" {
+		path = filepath.Join("data", "organizations.yaml")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &YAMLSink{file: f}, nil
+}
+
+func (y *YAMLSink) Write(org Organization) error {
+	data, err := yaml.Marshal(org)
+	if err != nil {
+		return err
+	}
+
+	y.mu.Lock()
+	defer y.mu.Unlock()
+	if _, err := y.file.WriteString("---\n"); err != nil {
+		return err
+	}
+	_, err = y.file.Write(data)
+	return err
+}
+
+func (y *YAMLSink) Close() error {
+	return y.file.Close()
+}
+
+// csvColumns is CSVSink's flattened schema. Category names are joined
+// with "; " since CSV has no native list type; the nested social links
+// are flattened into one column per platform (Facebook, Twitter,
+// Instagram and YouTube only).
+var csvColumns = []string{
+	"id", "name", "websiteKey", "email", "status", "visibility",
+	"organizationTypeId", "categoryNames", "modifiedOn",
+	"facebookUrl", "twitterUrl", "instagramUrl", "youtubeUrl",
+	"profilePictureUrl",
+}
+
+// CSVSink appends a flattened row per org to a single CSV file, writing
+// the header once when the file is first created.
+type CSVSink struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *csv.Writer
+}
+
+// NewCSVSink opens (creating and appending to) path (default:
+// data/organizations.csv).
+func NewCSVSink(path string) (*CSVSink, error) {
+	if path == "" {
+		path = filepath.Join("data", "organizations.csv")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	writeHeader := true
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		writeHeader = false
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	w := csv.NewWriter(f)
+	if writeHeader {
+		if err := w.Write(csvColumns); err != nil {
+			f.Close()
+			return nil, err
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	return &CSVSink{file: f, writer: w}, nil
+}
+
+func (c *CSVSink) Write(org Organization) error {
+	row := []string{
+		fmt.Sprintf("%v", org.ID),
+		org.Name,
+		org.WebsiteKey,
+		org.Email,
+		org.Status,
+		org.Visibility,
+		fmt.Sprintf("%d", org.OrganizationTypeID),
+		strings.Join(org.CategoryNames, "; "),
+		org.ModifiedOn,
+		org.SocialMedia.FacebookUrl,
+		org.SocialMedia.TwitterUrl,
+		org.SocialMedia.InstagramUrl,
+		org.SocialMedia.YoutubeUrl,
+		org.ProfilePictureURL,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.writer.Write(row); err != nil {
+		return err
+	}
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *CSVSink) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return err
+	}
+	return c.file.Close()
+}
+
+// SQLiteSink writes orgs (full record, JSON-encoded, plus a few indexed
+// columns) and officers (one row per org's primary contact) tables to a
+// CGO-free SQLite database, the same driver store.go's SQLStore uses.
+type SQLiteSink struct {
+	mu sync.Mutex
+	db *sql.DB
+}
+
+// NewSQLiteSink opens (creating and migrating) path (default:
+// data/organizations.sqlite).
+func NewSQLiteSink(path string) (*SQLiteSink, error) {
+	if path == "" {
+		path = filepath.Join("data", "organizations.sqlite")
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite output %s: %w", path, err)
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS orgs (
+			org_id TEXT PRIMARY KEY,
+			name TEXT,
+			website_key TEXT,
+			email TEXT,
+			status TEXT,
+			modified_on TEXT,
+			data TEXT NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS officers (
+			org_id TEXT,
+			contact_id TEXT,
+			first_name TEXT,
+			last_name TEXT,
+			email TEXT,
+			privacy TEXT
+		);
+		CREATE INDEX IF NOT EXISTS idx_officers_org_id ON officers (org_id);
+	`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate sqlite output %s: %w", path, err)
+	}
+
+	return &SQLiteSink{db: db}, nil
+}
+
+func (s *SQLiteSink) Write(org Organization) error {
+	data, err := json.Marshal(org)
+	if err != nil {
+		return err
+	}
+	orgID := fmt.Sprintf("%v", org.ID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO orgs (org_id, name, website_key, email, status, modified_on, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (org_id) DO UPDATE SET
+			name = excluded.name,
+			website_key = excluded.website_key,
+			email = excluded.email,
+			status = excluded.status,
+			modified_on = excluded.modified_on,
+			data = excluded.data
+	`, orgID, org.Name, org.WebsiteKey, org.Email, org.Status, org.ModifiedOn, string(data))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM officers WHERE org_id = ?`, orgID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if org.PrimaryContact.ID != "" {
+		_, err = tx.Exec(`
+			INSERT INTO officers (org_id, contact_id, first_name, last_name, email, privacy)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, orgID, org.PrimaryContact.ID, org.PrimaryContact.FirstName, org.PrimaryContact.LastName,
+			org.PrimaryContact.PrimaryEmailAddress, org.PrimaryContact.Privacy)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.Close()
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// HostLimiter rate-limits outbound requests per-host, honoring
+// Retry-After on 429/503 responses and backing off the effective rate
+// (AIMD-style) when an origin starts returning repeated 5xx.
+type HostLimiter struct {
+	mu         sync.Mutex
+	limiters   map[string]*hostState
+	baseRPS    rate.Limit
+	burst      int
+	maxBackoff time.Duration
+}
+
+type hostState struct {
+	limiter      *rate.Limiter
+	errorStreak  int
+	coolingUntil time.Time
+}
+
+// NewHostLimiter creates a limiter that allows rps requests/sec (burst
+// per host) by default, ramping down to a fraction of that during
+// cool-off windows after repeated 5xx responses.
+func NewHostLimiter(rps float64, burst int, maxBackoff time.Duration) *HostLimiter {
+	return &HostLimiter{
+		limiters:   make(map[string]*hostState),
+		baseRPS:    rate.Limit(rps),
+		burst:      burst,
+		maxBackoff: maxBackoff,
+	}
+}
+
+func (h *HostLimiter) stateFor(host string) *hostState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	st, ok := h.limiters[host]
+	if !ok {
+		st = &hostState{limiter: rate.NewLimiter(h.baseRPS, h.burst)}
+		h.limiters[host] = st
+	}
+	return st
+}
+
+// Wait blocks until a request to rawURL's host is permitted to proceed,
+// respecting any active cool-off window and the token-bucket limiter, or
+// returns early if ctx is canceled.
+func (h *HostLimiter) Wait(ctx context.Context, rawURL string) error {
+	host := hostOf(rawURL)
+	st := h.stateFor(host)
+
+	h.mu.Lock()
+	coolingUntil := st.coolingUntil
+	h.mu.Unlock()
+	if until := time.Until(coolingUntil); until > 0 {
+		timer := time.NewTimer(until)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return st.limiter.Wait(ctx)
+}
+
+// ReportSuccess restores the host's base rate in one step on the first
+// healthy response after a failure, undoing any earlier halving; the rate
+// is not ramped back up gradually.
+func (h *HostLimiter) ReportSuccess(rawURL string) {
+	st := h.stateFor(hostOf(rawURL))
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if st.errorStreak > 0 {
+		st.errorStreak = 0
+		st.limiter.SetLimit(h.baseRPS)
+	}
+}
+
+// ReportFailure halves the effective rate for this host on the second and
+// every later consecutive retryable failure (429 or 5xx under
+// DefaultRetryOn), a multiplicative decrease that lasts until the next
+// ReportSuccess, and — when resp carries a Retry-After header — blocks
+// the host until that deadline regardless of the token bucket.
+func (h *HostLimiter) ReportFailure(rawURL string, resp *http.Response) {
+	st := h.stateFor(hostOf(rawURL))
+
+	h.mu.Lock()
+	st.errorStreak++
+	if st.errorStreak >= 2 {
+		newLimit := st.limiter.Limit() / 2
+		if newLimit <= 0 {
+			newLimit = rate.Limit(0.1)
+		}
+		st.limiter.SetLimit(newLimit)
+	}
+	h.mu.Unlock()
+
+	if resp == nil {
+		return
+	}
+	if wait, ok := parseRetryAfter(resp); ok {
+		if wait > h.maxBackoff {
+			wait = h.maxBackoff
+		}
+		h.mu.Lock()
+		st.coolingUntil = time.Now().Add(wait)
+		h.mu.Unlock()
+	}
+}
+
+// BackoffDelay returns a full-jitter exponential backoff delay for the
+// given (zero-indexed) retry attempt, capped at maxBackoff.
+func BackoffDelay(attempt int, base, maxBackoff time.Duration) time.Duration {
+	if attempt < 0 {
+		attempt = 0
+	}
+	capped := math.Min(float64(maxBackoff), float64(base)*math.Pow(2, float64(attempt)))
+	return time.Duration(rand.Int63n(int64(capped) + 1))
+}
+
+// FetchPolicy formalizes the rate limit, retry and backoff behavior a
+// Scraper applies to its listing and detail-page fetches, and is the
+// single source of truth fetchOrganizations/enrichOrganization retry
+// against (replacing the ScraperConfig fields they used to read
+// directly). getTotalCount and the media/social requests are not retried
+// under it. The zero value is not usable; build one with
+// DefaultFetchPolicy or WithFetchPolicy.
+type FetchPolicy struct {
+	RateLimit   rate.Limit
+	Burst       int
+	MaxRetries  int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// RetryOn decides whether a response/error is worth retrying. nil
+	// selects DefaultRetryOn.
+	RetryOn func(resp *http.Response, err error) bool
+}
+
+// DefaultFetchPolicy builds a FetchPolicy from the legacy
+// ScraperConfig rate-limit/retry fields, for scrapers that don't call
+// WithFetchPolicy.
+func DefaultFetchPolicy(rps float64, burst, maxRetries int, baseBackoff, maxBackoff time.Duration) FetchPolicy {
+	return FetchPolicy{
+		RateLimit:   rate.Limit(rps),
+		Burst:       burst,
+		MaxRetries:  maxRetries,
+		BaseBackoff: baseBackoff,
+		MaxBackoff:  maxBackoff,
+		RetryOn:     DefaultRetryOn,
+	}
+}
+
+// DefaultRetryOn retries on net.Error timeouts and on 429/5xx responses.
+// context.Canceled/context.DeadlineExceeded are deliberately not
+// retried — they mean the caller (a SIGINT/SIGTERM shutdown or an
+// outer ctx deadline) wants the request abandoned, not retried.
+func DefaultRetryOn(resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout()
+	}
+	if resp == nil {
+		return false
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// parseRetryAfter parses the Retry-After header in both its delta-seconds
+// and HTTP-date forms, per RFC 9110 §10.2.3.
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := time.ParseDuration(v + "s"); err == nil {
+		return secs, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Host
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"ascii", "Some Club Name"},
+		{"emoji", "🎉 Party Planning Committee 🎉"},
+		{"rtl", "نادي الطلاب العرب"},
+		{"path separators", `a/b\c:d*e?f"g<h>i|j`},
+		{"empty", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizeFilename(tt.input)
+			if !utf8.ValidString(got) {
+				t.Fatalf("SanitizeFilename(%q) = %q, not valid UTF-8", tt.input, got)
+			}
+			for _, bad := range []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|", " "} {
+				if strings.Contains(got, bad) {
+					t.Errorf("SanitizeFilename(%q) = %q still contains %q", tt.input, got, bad)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitizeFilenameReservedDeviceNames(t *testing.T) {
+	for _, name := range []string{"CON", "con", "PRN", "AUX", "NUL", "COM1", "LPT9"} {
+		got := SanitizeFilename(name)
+		if strings.EqualFold(got, name) {
+			t.Errorf("SanitizeFilename(%q) = %q, want a suffixed, non-reserved name", name, got)
+		}
+	}
+}
+
+func TestSanitizeFilenameTruncationIsCollisionResistant(t *testing.T) {
+	prefix := strings.Repeat("a", maxFilenameRunes+10)
+	a := SanitizeFilename(prefix + "-one")
+	b := SanitizeFilename(prefix + "-two")
+
+	if a == b {
+		t.Fatalf("SanitizeFilename collided for two distinct long names: %q == %q", a, b)
+	}
+	if utf8.RuneCountInString(a) > maxFilenameRunes+9 {
+		t.Errorf("SanitizeFilename(%q) = %q is longer than expected", prefix+"-one", a)
+	}
+}
+
+func TestSanitizeFilenameRuneBoundary(t *testing.T) {
+	// Each "é" is a single rune but two UTF-8 bytes; a byte-index
+	// truncation at an odd offset would split one in half.
+	input := strings.Repeat("é", maxFilenameRunes+5)
+
+	got := SanitizeFilename(input)
+	if !utf8.ValidString(got) {
+		t.Fatalf("SanitizeFilename(%q) produced invalid UTF-8: %q", input, got)
+	}
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// OrgIndex is an in-memory, queryable mirror of the scraped organizations,
+// rebuilt from data/all_organizations_detailed.json (falling back to the
+// per-org data/org_*.json files if the combined file isn't present yet).
+type OrgIndex struct {
+	mu         sync.RWMutex
+	orgs       []Organization
+	byID       map[string]*Organization
+	byWebsite  map[string]*Organization
+	byCategory map[string][]*Organization
+	dataDir    string
+	loadedAt   time.Time
+}
+
+// NewOrgIndex builds an empty index rooted at dataDir and performs an
+// initial load.
+func NewOrgIndex(dataDir string) (*OrgIndex, error) {
+	idx := &OrgIndex{dataDir: dataDir}
+	if err := idx.Reload(); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Reload re-reads the combined JSON file (or the per-org files) and
+// atomically swaps the in-memory index. It's safe to call concurrently
+// with Query/Get from serving goroutines.
+func (idx *OrgIndex) Reload() error {
+	orgs, err := loadOrganizationsFromDisk(idx.dataDir)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]*Organization, len(orgs))
+	byWebsite := make(map[string]*Organization, len(orgs))
+	byCategory := make(map[string][]*Organization)
+
+	for i := range orgs {
+		org := &orgs[i]
+		byID[fmt.Sprintf("%v", org.ID)] = org
+		if org.WebsiteKey != "" {
+			byWebsite[org.WebsiteKey] = org
+		}
+		for _, catID := range org.CategoryIDs {
+			byCategory[catID] = append(byCategory[catID], org)
+		}
+	}
+
+	idx.mu.Lock()
+	idx.orgs = orgs
+	idx.byID = byID
+	idx.byWebsite = byWebsite
+	idx.byCategory = byCategory
+	idx.loadedAt = time.Now()
+	idx.mu.Unlock()
+
+	log.Printf("Index reloaded: %d organizations", len(orgs))
+	return nil
+}
+
+// loadOrganizationsFromDisk prefers the combined file since it's a single
+// read, and falls back to globbing the per-org files (the files the
+// default filesystem store writes) when it doesn't exist yet.
+func loadOrganizationsFromDisk(dataDir string) ([]Organization, error) {
+	combined := filepath.Join(dataDir, "all_organizations_detailed.json")
+	if data, err := os.ReadFile(combined); err == nil {
+		var orgs []Organization
+		if err := json.Unmarshal(data, &orgs); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", combined, err)
+		}
+		return orgs, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dataDir, "org_*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var orgs []Organization
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var org Organization
+		if err := json.Unmarshal(data, &org); err != nil {
+			continue
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, nil
+}
+
+// orgQuery captures the parsed filter/sort/pagination parameters accepted
+// by GET /api/orgs, mirroring the OData-ish shape of the upstream API.
+type orgQuery struct {
+	category  string
+	status    string
+	hasSocial string
+	q         string
+	sortBy    string
+	skip      int
+	top       int
+}
+
+func parseOrgQuery(r *http.Request) orgQuery {
+	v := r.URL.Query()
+
+	skip, _ := strconv.Atoi(v.Get("skip"))
+	top, err := strconv.Atoi(v.Get("top"))
+	if err != nil || top <= 0 {
+		top = 100
+	}
+
+	return orgQuery{
+		category:  v.Get("category"),
+		status:    v.Get("status"),
+		hasSocial: strings.ToLower(v.Get("hasSocial")),
+		q:         strings.ToLower(v.Get("q")),
+		sortBy:    v.Get("sortBy"),
+		skip:      skip,
+		top:       top,
+	}
+}
+
+// Query returns the organizations matching q, sorted and paginated.
+func (idx *OrgIndex) Query(q orgQuery) (matches []*Organization, total int) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	for i := range idx.orgs {
+		org := &idx.orgs[i]
+		if q.category != "" && !containsString(org.CategoryIDs, q.category) {
+			continue
+		}
+		if q.status != "" && !strings.EqualFold(org.Status, q.status) {
+			continue
+		}
+		if q.hasSocial != "" && !hasSocialLink(org.SocialMedia, q.hasSocial) {
+			continue
+		}
+		if q.q != "" && !matchesFullText(org, q.q) {
+			continue
+		}
+		matches = append(matches, org)
+	}
+
+	switch q.sortBy {
+	case "ModifiedOn":
+		sort.Slice(matches, func(i, j int) bool { return matches[i].ModifiedOn < matches[j].ModifiedOn })
+	default:
+		sort.Slice(matches, func(i, j int) bool { return matches[i].NameSortKey < matches[j].NameSortKey })
+	}
+
+	total = len(matches)
+
+	if q.skip >= len(matches) {
+		return nil, total
+	}
+	end := q.skip + q.top
+	if end > len(matches) {
+		end = len(matches)
+	}
+	return matches[q.skip:end], total
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSocialLink(sm SocialMedia, platform string) bool {
+	switch platform {
+	case "instagram":
+		return sm.InstagramUrl != ""
+	case "facebook":
+		return sm.FacebookUrl != ""
+	case "twitter", "x":
+		return sm.TwitterUrl != ""
+	case "youtube":
+		return sm.YoutubeUrl != ""
+	case "linkedin":
+		return sm.LinkedInUrl != ""
+	default:
+		return false
+	}
+}
+
+func matchesFullText(org *Organization, q string) bool {
+	return strings.Contains(strings.ToLower(org.Name), q) ||
+		strings.Contains(strings.ToLower(org.Description), q) ||
+		strings.Contains(strings.ToLower(org.Summary), q)
+}
+
+// Get returns the organization with the given CalLink ID.
+func (idx *OrgIndex) Get(id string) (*Organization, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	org, ok := idx.byID[id]
+	return org, ok
+}
+
+// GetByWebsiteKey returns the organization with the given websiteKey.
+func (idx *OrgIndex) GetByWebsiteKey(key string) (*Organization, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	org, ok := idx.byWebsite[key]
+	return org, ok
+}
+
+// ByCategory returns the organizations tagged with the given category ID.
+func (idx *OrgIndex) ByCategory(id string) []*Organization {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.byCategory[id]
+}
+
+// pagedResponse mirrors the @odata.count/value shape of the upstream
+// CalLink API so existing consumers can point at this server unchanged.
+type pagedResponse struct {
+	Count int             `json:"@odata.count"`
+	Value []*Organization `json:"value"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
+
+// serveCmd implements the `scraper serve` subcommand: it builds an
+// OrgIndex over the scrape output and serves it as a small REST API.
+func serveCmd(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "Address to listen on")
+	dataDir := fs.String("data", "data", "Directory containing scraped organization JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	idx, err := NewOrgIndex(*dataDir)
+	if err != nil {
+		return fmt.Errorf("failed to build initial index: %w", err)
+	}
+
+	// SIGHUP triggers a reload so a fresh scrape run is picked up without
+	// restarting the server.
+	reloadSig := make(chan os.Signal, 1)
+	signal.Notify(reloadSig, syscall.SIGHUP)
+	go func() {
+		for range reloadSig {
+			log.Println("Received SIGHUP, reloading index...")
+			if err := idx.Reload(); err != nil {
+				log.Printf("Reload failed: %v", err)
+			}
+		}
+	}()
+
+	// Fall back to a cheap mtime poll in case the operator never sends a
+	// signal (e.g. the scraper and server run as unrelated processes).
+	go pollForChanges(idx, *dataDir, 30*time.Second)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/orgs", func(w http.ResponseWriter, r *http.Request) {
+		q := parseOrgQuery(r)
+		matches, total := idx.Query(q)
+		writeJSON(w, http.StatusOK, pagedResponse{Count: total, Value: matches})
+	})
+	mux.HandleFunc("/api/orgs/by-websitekey/", func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/api/orgs/by-websitekey/")
+		org, ok := idx.GetByWebsiteKey(key)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, http.StatusOK, org)
+	})
+	mux.HandleFunc("/api/orgs/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/api/orgs/")
+		org, ok := idx.Get(id)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, http.StatusOK, org)
+	})
+	mux.HandleFunc("/api/categories/", func(w http.ResponseWriter, r *http.Request) {
+		rest := strings.TrimPrefix(r.URL.Path, "/api/categories/")
+		id := strings.TrimSuffix(rest, "/orgs")
+		if id == rest {
+			http.NotFound(w, r)
+			return
+		}
+		orgs := idx.ByCategory(id)
+		writeJSON(w, http.StatusOK, pagedResponse{Count: len(orgs), Value: orgs})
+	})
+
+	log.Printf("Serving organization API on %s (data dir: %s)", *addr, *dataDir)
+	return http.ListenAndServe(*addr, mux)
+}
+
+// pollForChanges reloads the index whenever the combined file's mtime
+// advances, as a fallback for operators who don't wire up SIGHUP.
+func pollForChanges(idx *OrgIndex, dataDir string, interval time.Duration) {
+	combined := filepath.Join(dataDir, "all_organizations_detailed.json")
+	info, err := os.Stat(combined)
+	var lastMod time.Time
+	if err == nil {
+		lastMod = info.ModTime()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		info, err := os.Stat(combined)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(lastMod) {
+			lastMod = info.ModTime()
+			if err := idx.Reload(); err != nil {
+				log.Printf("Reload failed: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// SocialPlatform captures what the enrichment worker learned about one
+// social link: whether it resolved, and (when credentials allow) basic
+// public profile metadata.
+type SocialPlatform struct {
+	Handle     string `json:"handle,omitempty"`
+	Verified   bool   `json:"verified"`
+	Followers  int    `json:"followers,omitempty"`
+	LastPostAt string `json:"lastPostAt,omitempty"`
+	BrokenLink bool   `json:"brokenLink"`
+}
+
+// SocialMediaEnriched mirrors SocialMedia's platforms, but with
+// normalized/verified metadata instead of the raw URLs CalLink hands us.
+type SocialMediaEnriched struct {
+	Facebook  *SocialPlatform `json:"facebook,omitempty"`
+	Twitter   *SocialPlatform `json:"twitter,omitempty"`
+	Instagram *SocialPlatform `json:"instagram,omitempty"`
+	YouTube   *SocialPlatform `json:"youtube,omitempty"`
+	Mastodon  *SocialPlatform `json:"mastodon,omitempty"`
+}
+
+// trackingParams are stripped from social URLs before anything else.
+var trackingParams = []string{"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content", "igshid", "fbclid"}
+
+// normalizeSocialURL strips tracking params and canonicalizes
+// twitter.com (and www.twitter.com) to x.com.
+func normalizeSocialURL(raw string) string {
+	if raw == "" {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	q := u.Query()
+	for _, p := range trackingParams {
+		q.Del(p)
+	}
+	u.RawQuery = q.Encode()
+
+	switch u.Host {
+	case "twitter.com", "www.twitter.com":
+		u.Host = "x.com"
+	}
+
+	return u.String()
+}
+
+// SocialEnricher normalizes and verifies the social links on an org,
+// optionally pulling lightweight public metadata when the relevant API
+// credentials are present in the environment. All network calls share
+// the scraper's per-host rate limiter/backoff.
+type SocialEnricher struct {
+	client  *http.Client
+	limiter *HostLimiter
+}
+
+// NewSocialEnricher builds an enricher sharing client/limiter with the
+// rest of the scraper.
+func NewSocialEnricher(client *http.Client, limiter *HostLimiter) *SocialEnricher {
+	return &SocialEnricher{client: client, limiter: limiter}
+}
+
+// Enrich normalizes org.SocialMedia's URLs in place and returns the
+// populated SocialMediaEnriched sibling.
+func (e *SocialEnricher) Enrich(ctx context.Context, sm *SocialMedia) SocialMediaEnriched {
+	sm.FacebookUrl = normalizeSocialURL(sm.FacebookUrl)
+	sm.TwitterUrl = normalizeSocialURL(sm.resolveShortlink(ctx, e, sm.TwitterUrl))
+	sm.InstagramUrl = normalizeSocialURL(sm.InstagramUrl)
+	sm.YoutubeUrl = normalizeSocialURL(sm.YoutubeUrl)
+
+	var enriched SocialMediaEnriched
+	if sm.FacebookUrl != "" {
+		enriched.Facebook = e.checkPlatform(ctx, sm.FacebookUrl)
+	}
+	if sm.TwitterUrl != "" {
+		enriched.Twitter = e.enrichTwitter(ctx, sm.TwitterUrl)
+	}
+	if sm.InstagramUrl != "" {
+		enriched.Instagram = e.enrichInstagram(ctx, sm.InstagramUrl)
+	}
+	if sm.YoutubeUrl != "" {
+		enriched.YouTube = e.enrichYouTube(ctx, sm.YoutubeUrl)
+	}
+	if mastodonURL, ok := detectMastodonInstance(ctx, e, sm); ok {
+		enriched.Mastodon = e.enrichMastodon(ctx, mastodonURL)
+	}
+
+	return enriched
+}
+
+// resolveShortlink follows redirects for known link shorteners
+// (linktr.ee, bit.ly) so downstream checks see the real destination.
+func (sm *SocialMedia) resolveShortlink(ctx context.Context, e *SocialEnricher, raw string) string {
+	if raw == "" {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	if u.Host != "linktr.ee" && u.Host != "bit.ly" {
+		return raw
+	}
+
+	if err := e.limiter.Wait(ctx, raw); err != nil {
+		return raw
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, raw, nil)
+	if err != nil {
+		return raw
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return raw
+	}
+	defer resp.Body.Close()
+	if loc := resp.Request.URL.String(); loc != "" {
+		return loc
+	}
+	return raw
+}
+
+// checkPlatform does a generic HEAD-request liveness check for platforms
+// without a richer metadata API.
+func (e *SocialEnricher) checkPlatform(ctx context.Context, rawURL string) *SocialPlatform {
+	if err := e.limiter.Wait(ctx, rawURL); err != nil {
+		return &SocialPlatform{BrokenLink: true}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		return &SocialPlatform{BrokenLink: true}
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return &SocialPlatform{BrokenLink: true}
+	}
+	defer resp.Body.Close()
+	return &SocialPlatform{
+		Handle:     handleFromURL(rawURL),
+		Verified:   resp.StatusCode < 400,
+		BrokenLink: resp.StatusCode >= 400,
+	}
+}
+
+// enrichTwitter validates a handle via the public oEmbed endpoint.
+func (e *SocialEnricher) enrichTwitter(ctx context.Context, rawURL string) *SocialPlatform {
+	handle := handleFromURL(rawURL)
+	oembedURL := fmt.Sprintf("https://publish.twitter.com/oembed?url=%s", url.QueryEscape(rawURL))
+
+	if err := e.limiter.Wait(ctx, oembedURL); err != nil {
+		return &SocialPlatform{Handle: handle, BrokenLink: true}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, oembedURL, nil)
+	if err != nil {
+		return &SocialPlatform{Handle: handle, BrokenLink: true}
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return &SocialPlatform{Handle: handle, BrokenLink: true}
+	}
+	defer resp.Body.Close()
+
+	return &SocialPlatform{
+		Handle:     handle,
+		Verified:   resp.StatusCode == http.StatusOK,
+		BrokenLink: resp.StatusCode != http.StatusOK,
+	}
+}
+
+// igGraphResponse is the subset of the Instagram Graph API basic display
+// response this enricher cares about.
+type igGraphResponse struct {
+	Username      string `json:"username"`
+	FollowerCount int    `json:"followers_count"`
+}
+
+// enrichInstagram pulls basic profile info via the Instagram Graph API
+// when INSTAGRAM_ACCESS_TOKEN is set; otherwise falls back to a liveness
+// check only.
+func (e *SocialEnricher) enrichInstagram(ctx context.Context, rawURL string) *SocialPlatform {
+	token := os.Getenv("INSTAGRAM_ACCESS_TOKEN")
+	handle := handleFromURL(rawURL)
+	if token == "" {
+		return e.checkPlatform(ctx, rawURL)
+	}
+
+	apiURL := fmt.Sprintf("https://graph.instagram.com/me?fields=username,followers_count&access_token=%s", token)
+	if err := e.limiter.Wait(ctx, apiURL); err != nil {
+		return &SocialPlatform{Handle: handle, BrokenLink: true}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return &SocialPlatform{Handle: handle, BrokenLink: true}
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return &SocialPlatform{Handle: handle, BrokenLink: true}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &SocialPlatform{Handle: handle, BrokenLink: true}
+	}
+
+	var data igGraphResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return &SocialPlatform{Handle: handle, Verified: true}
+	}
+	return &SocialPlatform{Handle: data.Username, Verified: true, Followers: data.FollowerCount}
+}
+
+// ytChannelResponse is the subset of the YouTube Data API's
+// channels.list response this enricher cares about.
+type ytChannelResponse struct {
+	Items []struct {
+		Snippet struct {
+			Title string `json:"title"`
+		} `json:"snippet"`
+		Statistics struct {
+			SubscriberCount string `json:"subscriberCount"`
+		} `json:"statistics"`
+	} `json:"items"`
+}
+
+// enrichYouTube pulls channel snippet+statistics via the YouTube Data API
+// when YOUTUBE_API_KEY is set; otherwise falls back to a liveness check.
+func (e *SocialEnricher) enrichYouTube(ctx context.Context, rawURL string) *SocialPlatform {
+	apiKey := os.Getenv("YOUTUBE_API_KEY")
+	handle := handleFromURL(rawURL)
+	if apiKey == "" {
+		return e.checkPlatform(ctx, rawURL)
+	}
+
+	apiURL := fmt.Sprintf("https://www.googleapis.com/youtube/v3/channels?part=snippet,statistics&forHandle=%s&key=%s", handle, apiKey)
+	if err := e.limiter.Wait(ctx, apiURL); err != nil {
+		return &SocialPlatform{Handle: handle, BrokenLink: true}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return &SocialPlatform{Handle: handle, BrokenLink: true}
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return &SocialPlatform{Handle: handle, BrokenLink: true}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &SocialPlatform{Handle: handle, BrokenLink: true}
+	}
+
+	var data ytChannelResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil || len(data.Items) == 0 {
+		return &SocialPlatform{Handle: handle, Verified: true}
+	}
+	item := data.Items[0]
+	followers := 0
+	fmt.Sscanf(item.Statistics.SubscriberCount, "%d", &followers)
+	return &SocialPlatform{Handle: item.Snippet.Title, Verified: true, Followers: followers}
+}
+
+// detectMastodonInstance checks whether ExternalWebsite is a Mastodon
+// instance via .well-known/nodeinfo, and if so returns its URL.
+func detectMastodonInstance(ctx context.Context, e *SocialEnricher, sm *SocialMedia) (string, bool) {
+	if sm.ExternalWebsite == "" {
+		return "", false
+	}
+	u, err := url.Parse(sm.ExternalWebsite)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+
+	nodeinfoURL := fmt.Sprintf("https://%s/.well-known/nodeinfo", u.Host)
+	if err := e.limiter.Wait(ctx, nodeinfoURL); err != nil {
+		return "", false
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, nodeinfoURL, nil)
+	if err != nil {
+		return "", false
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return "", false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", false
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", false
+	}
+	if !strings.Contains(string(body), "mastodon") {
+		return "", false
+	}
+	return sm.ExternalWebsite, true
+}
+
+// enrichMastodon looks up an account via /api/v1/accounts/lookup. The
+// account handle is the last path segment of instanceURL (e.g. "@org"
+// in "https://mastodon.social/@org"), not the bare instance hostname.
+func (e *SocialEnricher) enrichMastodon(ctx context.Context, instanceURL string) *SocialPlatform {
+	u, err := url.Parse(instanceURL)
+	if err != nil {
+		return nil
+	}
+
+	handle := strings.TrimPrefix(handleFromURL(instanceURL), "@")
+	if handle == "" {
+		return &SocialPlatform{BrokenLink: true}
+	}
+
+	lookupURL := fmt.Sprintf("https://%s/api/v1/accounts/lookup?acct=%s", u.Host, url.QueryEscape(handle))
+	if err := e.limiter.Wait(ctx, lookupURL); err != nil {
+		log.Printf("Mastodon lookup failed for %s: %v", instanceURL, err)
+		return &SocialPlatform{BrokenLink: true}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, lookupURL, nil)
+	if err != nil {
+		return &SocialPlatform{BrokenLink: true}
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return &SocialPlatform{BrokenLink: true}
+	}
+	defer resp.Body.Close()
+	return &SocialPlatform{
+		Handle:     handle,
+		Verified:   resp.StatusCode == http.StatusOK,
+		BrokenLink: resp.StatusCode != http.StatusOK,
+	}
+}
+
+// handleFromURL returns the last non-empty path segment of a social URL,
+// used as a best-effort handle when an API doesn't hand us a canonical one.
+func handleFromURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) == 0 {
+		return ""
+	}
+	return parts[len(parts)-1]
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	_ "github.com/lib/pq"  // postgres driver
+	_ "modernc.org/sqlite" // CGO-free sqlite driver
+)
+
+// Store abstracts the persistence layer so the scraper isn't tied to one
+// JSON file per organization. saveOrganization/saveCombinedFile/loadProgress/
+// saveProgress all go through a Store, selected at startup via --store.
+type Store interface {
+	UpsertOrganization(org Organization) error
+	GetOrganization(id string) (Organization, bool, error)
+	ListOrganizations() ([]Organization, error)
+	SaveProgress(p Progress) error
+	LoadProgress() (Progress, error)
+	Close() error
+}
+
+// NewStore parses a --store DSN and returns the matching Store
+// implementation. Supported schemes:
+//
+//	(empty)      -> filesystem store rooted at dataDir (current behavior)
+//	sqlite://path  -> SQLite store, e.g. sqlite://data/callink.db
+//	postgres://... -> Postgres store, passed straight to database/sql
+//
+// progressPath overrides the filesystem store's progress.json location;
+// pass "" to use the default (dataDir/../progress.json). It's ignored by
+// the SQL-backed stores, which keep progress in their own progress table.
+func NewStore(dsn string, dataDir string, progressPath string) (Store, error) {
+	if dsn == "" {
+		return NewFileStore(dataDir, progressPath), nil
+	}
+
+	scheme, rest, ok := strings.Cut(dsn, "://")
+	if !ok {
+		return nil, fmt.Errorf("invalid --store DSN %q: missing scheme", dsn)
+	}
+
+	switch scheme {
+	case "sqlite":
+		return NewSQLStore("sqlite", rest)
+	case "postgres":
+		return NewSQLStore("postgres", dsn)
+	default:
+		return nil, fmt.Errorf("unsupported store scheme %q", scheme)
+	}
+}
+
+// FileStore is the original one-file-per-organization layout, kept as the
+// default so existing scrape directories keep working unmodified.
+type FileStore struct {
+	dataDir      string
+	progressPath string
+}
+
+// NewFileStore returns a Store backed by the filesystem layout.
+// progressPath overrides where progress is persisted; pass "" for the
+// default of dataDir/../progress.json.
+func NewFileStore(dataDir, progressPath string) *FileStore {
+	if progressPath == "" {
+		progressPath = filepath.Join(dataDir, "..", "progress.json")
+	}
+	return &FileStore{dataDir: dataDir, progressPath: progressPath}
+}
+
+func (f *FileStore) orgPath(org Organization) string {
+	filename := fmt.Sprintf("org_%v.json", org.ID)
+	if org.WebsiteKey != "" && org.WebsiteKey != "null" {
+		filename = fmt.Sprintf("org_%v_%s.json", org.ID, SanitizeFilename(org.WebsiteKey))
+	}
+	return filepath.Join(f.dataDir, filename)
+}
+
+func (f *FileStore) UpsertOrganization(org Organization) error {
+	data, err := json.MarshalIndent(org, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.orgPath(org), data, 0644)
+}
+
+// GetOrganization looks up id directly via orgPath's naming scheme
+// (org_<id>.json or org_<id>_<websiteKey>.json) instead of unmarshaling
+// every file in dataDir, so incremental mode's per-org changelog lookups
+// read and decode only one file. The org_<id>_*.json fallback still
+// lists dataDir's entries to match the pattern.
+func (f *FileStore) GetOrganization(id string) (Organization, bool, error) {
+	candidates, err := filepath.Glob(filepath.Join(f.dataDir, fmt.Sprintf("org_%s.json", id)))
+	if err != nil {
+		return Organization{}, false, err
+	}
+	if len(candidates) == 0 {
+		candidates, err = filepath.Glob(filepath.Join(f.dataDir, fmt.Sprintf("org_%s_*.json", id)))
+		if err != nil {
+			return Organization{}, false, err
+		}
+	}
+	if len(candidates) == 0 {
+		return Organization{}, false, nil
+	}
+
+	data, err := os.ReadFile(candidates[0])
+	if err != nil {
+		return Organization{}, false, err
+	}
+	var org Organization
+	if err := json.Unmarshal(data, &org); err != nil {
+		return Organization{}, false, err
+	}
+	return org, true, nil
+}
+
+func (f *FileStore) ListOrganizations() ([]Organization, error) {
+	files, err := filepath.Glob(filepath.Join(f.dataDir, "org_*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var orgs []Organization
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var org Organization
+		if err := json.Unmarshal(data, &org); err != nil {
+			continue
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, nil
+}
+
+// writeFileAtomic writes data to a temp file in path's directory, fsyncs
+// it, then renames it over path. A process killed mid-write leaves either
+// the old contents or the new ones in place, never a truncated file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once the rename below succeeds
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func (f *FileStore) SaveProgress(p Progress) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(f.progressPath, data, 0644)
+}
+
+func (f *FileStore) LoadProgress() (Progress, error) {
+	var p Progress
+	data, err := os.ReadFile(f.progressPath)
+	if err != nil {
+		return p, err
+	}
+	err = json.Unmarshal(data, &p)
+	return p, err
+}
+
+func (f *FileStore) Close() error { return nil }
+
+// SQLStore backs the Store interface with database/sql, and is used for
+// both the SQLite (CGO-free, via modernc.org/sqlite) and Postgres drivers.
+// Each org is stored whole, nested types (SocialMedia, ContactInfo,
+// Categories) included, as one JSON document in the data column (JSONB on
+// Postgres, TEXT on SQLite) rather than in joined child tables, since the
+// scraper only ever needs to round-trip them whole.
+type SQLStore struct {
+	mu     sync.Mutex // serializes writes; mirrors SQLiteSink's mu in output.go
+	db     *sql.DB
+	driver string
+}
+
+// NewSQLStore opens (and migrates) a SQL-backed store. driver is either
+// "sqlite" (dsn is a filesystem path) or "postgres" (dsn is a full
+// connection URL).
+func NewSQLStore(driver, dsn string) (*SQLStore, error) {
+	sqlDriver := driver
+	if driver == "sqlite" {
+		sqlDriver = "sqlite" // modernc.org/sqlite registers itself as "sqlite"
+		dsn = sqliteDSNWithPragmas(dsn)
+	}
+
+	db, err := sql.Open(sqlDriver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s store: %w", driver, err)
+	}
+	if driver == "sqlite" {
+		// Workers call UpsertOrganization concurrently; modernc.org/sqlite
+		// only allows one writer connection at a time, so cap the pool to
+		// avoid SQLITE_BUSY errors from concurrent connections racing for
+		// the write lock. busy_timeout (set via DSN above) then covers the
+		// remaining case of a write overlapping a long-running read.
+		db.SetMaxOpenConns(1)
+	}
+
+	s := &SQLStore{db: db, driver: driver}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate %s store: %w", driver, err)
+	}
+	return s, nil
+}
+
+// sqliteDSNWithPragmas appends busy_timeout and WAL-mode pragmas to a
+// sqlite DSN, unless the DSN already sets busy_timeout, so
+// concurrent UpsertOrganization calls from detail-fetch workers block
+// and retry instead of failing with SQLITE_BUSY.
+func sqliteDSNWithPragmas(dsn string) string {
+	if strings.Contains(dsn, "_pragma=busy_timeout") {
+		return dsn
+	}
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	return dsn + sep + "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+}
+
+func (s *SQLStore) migrate() error {
+	jsonType := "TEXT"
+	if s.driver == "postgres" {
+		jsonType = "JSONB"
+	}
+
+	_, err := s.db.Exec(fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS orgs (
+			id TEXT PRIMARY KEY,
+			website_key TEXT,
+			category_ids %s,
+			organization_type_id INTEGER,
+			modified_on TEXT,
+			data %s NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_orgs_category_ids ON orgs (category_ids);
+		CREATE INDEX IF NOT EXISTS idx_orgs_org_type ON orgs (organization_type_id);
+		CREATE TABLE IF NOT EXISTS progress (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			data %s NOT NULL
+		);
+	`, jsonType, jsonType, jsonType))
+	return err
+}
+
+func (s *SQLStore) placeholder(n int) string {
+	if s.driver == "postgres" {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+func (s *SQLStore) UpsertOrganization(org Organization) error {
+	data, err := json.Marshal(org)
+	if err != nil {
+		return err
+	}
+	categoryIDs, err := json.Marshal(org.CategoryIDs)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO orgs (id, website_key, category_ids, organization_type_id, modified_on, data)
+		VALUES (%s, %s, %s, %s, %s, %s)
+		ON CONFLICT (id) DO UPDATE SET
+			website_key = excluded.website_key,
+			category_ids = excluded.category_ids,
+			organization_type_id = excluded.organization_type_id,
+			modified_on = excluded.modified_on,
+			data = excluded.data
+	`, s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4), s.placeholder(5), s.placeholder(6))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.db.Exec(query,
+		fmt.Sprintf("%v", org.ID), org.WebsiteKey, string(categoryIDs), org.OrganizationTypeID, org.ModifiedOn, string(data))
+	return err
+}
+
+func (s *SQLStore) GetOrganization(id string) (Organization, bool, error) {
+	var org Organization
+	var data string
+	query := fmt.Sprintf("SELECT data FROM orgs WHERE id = %s", s.placeholder(1))
+	err := s.db.QueryRow(query, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return org, false, nil
+	}
+	if err != nil {
+		return org, false, err
+	}
+	if err := json.Unmarshal([]byte(data), &org); err != nil {
+		return org, false, err
+	}
+	return org, true, nil
+}
+
+func (s *SQLStore) ListOrganizations() ([]Organization, error) {
+	rows, err := s.db.Query("SELECT data FROM orgs")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orgs []Organization
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var org Organization
+		if err := json.Unmarshal([]byte(data), &org); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, rows.Err()
+}
+
+func (s *SQLStore) SaveProgress(p Progress) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	var query string
+	if s.driver == "postgres" {
+		query = `
+			INSERT INTO progress (id, data) VALUES (1, $1)
+			ON CONFLICT (id) DO UPDATE SET data = excluded.data
+		`
+	} else {
+		query = `
+			INSERT INTO progress (id, data) VALUES (1, ?)
+			ON CONFLICT (id) DO UPDATE SET data = excluded.data
+		`
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.db.Exec(query, string(data))
+	return err
+}
+
+func (s *SQLStore) LoadProgress() (Progress, error) {
+	var p Progress
+	var data string
+	err := s.db.QueryRow("SELECT data FROM progress WHERE id = 1").Scan(&data)
+	if err == sql.ErrNoRows {
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	err = json.Unmarshal([]byte(data), &p)
+	return p, err
+}
+
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}